@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/robotii/lito/compiler"
+	"github.com/robotii/lito/compiler/bytecode"
 	"github.com/robotii/lito/compiler/parser"
 	"github.com/robotii/lito/repl"
 	"github.com/robotii/lito/vm"
@@ -23,9 +24,11 @@ func main() {
 	memprofile := flag.String("memprofile", "", "write memory profile to `file`")
 	traceprofile := flag.String("trace", "", "write trace to `file`")
 	inspect := flag.Bool("inspect", false, "show the generated instructions")
-	machineType := flag.String("mtype", "standard", "type of the machine to use")
+	noOptimise := flag.Bool("noopt", false, "don't optimise the generated instructions")
+	machineType := flag.String("mtype", "standard", "type of the machine to use: standard, sandbox or compiled")
 
 	flag.Parse()
+	bytecode.Optimise = !*noOptimise
 
 	if *versionOptionPtr {
 		fmt.Println(vm.Version)
@@ -65,6 +68,8 @@ func main() {
 	case "":
 		repl.StartREPL(vm.Version, *inspect, *machineType)
 		os.Exit(0)
+	case "check":
+		os.Exit(check(flag.Args()[1:]))
 	default:
 		fp = flag.Arg(0)
 
@@ -84,6 +89,12 @@ func main() {
 		instructionSets, err := compiler.CompileToInstructions(string(file), parser.NormalMode)
 		reportErrorAndExit(err)
 
+		if *inspect {
+			for _, is := range instructionSets {
+				fmt.Println(is.Inspect())
+			}
+		}
+
 		configs := []vm.ConfigFunc{vm.Mode(parser.CommandLineMode)}
 		if cfg, ok := vm.MachineConfigs[*machineType]; ok {
 			configs = append(configs, cfg)
@@ -118,6 +129,25 @@ func main() {
 	}
 }
 
+// check reports the type annotations obviously not matched in the files,
+// and returns the exit status
+func check(files []string) int {
+	status := 0
+	for _, fp := range files {
+		problems, err := compiler.Check(string(readFile(fp)))
+		if err != nil {
+			fmt.Printf("%s: %s\n", fp, err.Error())
+			status = 1
+			continue
+		}
+		for _, problem := range problems {
+			fmt.Printf("%s:%s\n", fp, problem)
+			status = 1
+		}
+	}
+	return status
+}
+
 func extractFileInfo(fp string) (dir, fileExt string) {
 	dir, _ = filepath.Split(fp)
 	dir, _ = filepath.Abs(dir)
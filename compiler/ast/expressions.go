@@ -64,6 +64,24 @@ func (sl *StringLiteral) String() string {
 	return out.String()
 }
 
+// RegexpLiteral contains the node expression and its pattern
+type RegexpLiteral struct {
+	*BaseNode
+	Value string
+}
+
+func (rl *RegexpLiteral) expressionNode() {}
+
+// TokenLiteral gets the literal of the Regexp type token
+func (rl *RegexpLiteral) TokenLiteral() string {
+	return rl.Token.Literal
+}
+
+// RegexpLiteral.String gets the string format of the Regexp type token
+func (rl *RegexpLiteral) String() string {
+	return "/" + rl.Token.Literal + "/"
+}
+
 // ArrayExpression defines the array expression literal which contains the node expression and its value
 type ArrayExpression struct {
 	*BaseNode
@@ -120,10 +138,51 @@ func (ape *ArgumentPairExpression) String() string {
 	return fmt.Sprintf("%s: %s", ape.Key.String(), ape.Value.String())
 }
 
+// TypedParameter represents a method parameter annotated with the class of its argument, like `a: Integer`
+type TypedParameter struct {
+	*BaseNode
+	Name *Identifier
+	Type Expression
+}
+
+func (tp *TypedParameter) expressionNode() {}
+
+// TokenLiteral returns the name of the parameter
+func (tp *TypedParameter) TokenLiteral() string {
+	return tp.Name.Value
+}
+
+// String returns the parameter with its type
+func (tp *TypedParameter) String() string {
+	return fmt.Sprintf("%s: %s", tp.Name.String(), tp.Type.String())
+}
+
+// ClassName returns the name of the class a constant or namespaced constant like `Foo::Bar` refers to,
+// or false if the expression isn't one
+func ClassName(exp Expression) (string, bool) {
+	switch exp := exp.(type) {
+	case *Constant:
+		return exp.Value, true
+	case *InfixExpression:
+		if exp.Operator != "::" {
+			return "", false
+		}
+		left, ok := ClassName(exp.Left)
+		if !ok {
+			return "", false
+		}
+		right, ok := ClassName(exp.Right)
+		return left + "::" + right, ok
+	}
+	return "", false
+}
+
 // HashExpression defines the hash expression literal which contains the node expression and its value
 type HashExpression struct {
 	*BaseNode
 	Data map[string]Expression
+	// Keys are the keys of the data in the order they are written
+	Keys []string
 }
 
 func (he *HashExpression) expressionNode() {}
@@ -138,8 +197,8 @@ func (he *HashExpression) String() string {
 	var out strings.Builder
 	var pairs []string
 
-	for key, value := range he.Data {
-		pairs = append(pairs, fmt.Sprintf("%s: %s", key, value.String()))
+	for _, key := range he.Keys {
+		pairs = append(pairs, fmt.Sprintf("%s: %s", key, he.Data[key].String()))
 	}
 
 	out.WriteString("{")
@@ -110,6 +110,8 @@ type DefStatement struct {
 	Receiver       Expression
 	Parameters     []Expression
 	BlockStatement *BlockStatement
+	// ReturnType is the class the method is annotated to return, if any
+	ReturnType Expression
 }
 
 func (ds *DefStatement) statementNode() {}
@@ -133,6 +135,11 @@ func (ds *DefStatement) String() string {
 	}
 
 	out.WriteString(") ")
+	if ds.ReturnType != nil {
+		out.WriteString("-> ")
+		out.WriteString(ds.ReturnType.String())
+		out.WriteString(" ")
+	}
 	out.WriteString("{\n")
 	out.WriteString(ds.BlockStatement.String())
 	out.WriteString("\n}")
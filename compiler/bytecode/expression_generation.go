@@ -36,6 +36,8 @@ func (g *Generator) compileExpression(is *InstructionSet, exp ast.Expression, sc
 		is.define(PutFloat, sourceLine, exp.Value)
 	case *ast.StringLiteral:
 		is.define(PutString, sourceLine, exp.Value)
+	case *ast.RegexpLiteral:
+		is.define(PutRegexp, sourceLine, exp.Value)
 	case *ast.BooleanExpression:
 		if exp.Value {
 			is.define(PutTrue, sourceLine)
@@ -58,9 +60,9 @@ func (g *Generator) compileExpression(is *InstructionSet, exp ast.Expression, sc
 		}
 		is.define(NewArray, sourceLine, len(exp.Elements))
 	case *ast.HashExpression:
-		for key, value := range exp.Data {
+		for _, key := range exp.Keys {
 			is.define(PutString, sourceLine, key)
-			g.compileExpression(is, value, scope, table)
+			g.compileExpression(is, exp.Data[key], scope, table)
 		}
 		is.define(NewHash, sourceLine, len(exp.Data)*2)
 	case *ast.SelfExpression:
@@ -101,7 +103,7 @@ func (g *Generator) compileIdentifier(is *InstructionSet, exp *ast.Identifier, s
 
 	// otherwise it's a method call
 	is.define(PutSelf, exp.Line())
-	is.define(Send, exp.Line(), exp.Value, 0, nil, nil)
+	is.define(Send, exp.Line(), exp.Value, 0, nil, nil, &CallSite{SelfReceiver: true})
 }
 
 func (g *Generator) compileYieldExpression(is *InstructionSet, exp *ast.YieldExpression, scope *scope, table *localTable) {
@@ -167,12 +169,16 @@ func (g *Generator) compileCallExpression(is *InstructionSet, exp *ast.CallExpre
 		blockIndex := g.blockCounter
 		g.blockCounter++
 		blockInfo = g.compileBlockArgExpression(blockIndex, exp, scope, newTable)
+		is.HasBlocks = true
 	}
 
 	if exp.Method == "defer" {
+		// Deferred blocks run with this frame as their environment
+		is.HasBlocks = true
 		is.define(Defer, exp.Line(), len(exp.Arguments), blockInfo)
 	} else {
-		is.define(Send, exp.Line(), exp.Method, len(exp.Arguments), blockInfo, argSet)
+		_, self := exp.Receiver.(*ast.SelfExpression)
+		is.define(Send, exp.Line(), exp.Method, len(exp.Arguments), blockInfo, argSet, &CallSite{SelfReceiver: self})
 	}
 }
 
@@ -211,10 +217,20 @@ func (g *Generator) compileAssignExpression(is *InstructionSet, exp *ast.AssignE
 }
 
 func (g *Generator) compileBlockArgExpression(index int, exp *ast.CallExpression, scope *scope, table *localTable) *InstructionSet {
-	is := &InstructionSet{Name: fmt.Sprint(index), Type: Block}
+	is := &InstructionSet{
+		Name: fmt.Sprint(index),
+		Type: Block,
+		// The block's parameters are needed when it is made into a method
+		ArgTypes: ArgSet{
+			names: make([]string, len(exp.BlockArguments)),
+			types: make([]uint8, len(exp.BlockArguments)),
+		},
+		locals: table,
+	}
 
 	for i := 0; i < len(exp.BlockArguments); i++ {
 		table.set(exp.BlockArguments[i].Value)
+		is.ArgTypes.setArg(i, exp.BlockArguments[i].Value, NormalArg)
 	}
 
 	g.compileCodeBlock(is, exp.Block, scope, table)
@@ -257,22 +273,22 @@ func (g *Generator) compileIfExpression(is *InstructionSet, exp *ast.IfExpressio
 
 func (g *Generator) compilePrefixExpression(is *InstructionSet, exp *ast.PrefixExpression, scope *scope, table *localTable) {
 	switch exp.Operator {
-	case "!":
+	case "!", "~":
 		g.compileExpression(is, exp.Right, scope, table)
-		is.define(Send, exp.Line(), exp.Operator, 0, nil, nil)
+		is.define(Send, exp.Line(), exp.Operator, 0, nil, nil, &CallSite{})
 	case "*":
 		g.compileExpression(is, exp.Right, scope, table)
 		is.define(SplatArray, exp.Line())
 	case "-":
 		is.define(PutInt, exp.Line(), 0)
 		g.compileExpression(is, exp.Right, scope, table)
-		is.define(Send, exp.Line(), exp.Operator, 1, nil, nil)
+		is.define(Send, exp.Line(), exp.Operator, 1, nil, nil, &CallSite{})
 	case "+":
 		g.compileExpression(is, exp.Right, scope, table)
 	case "<-", "->":
 		is.define(PutSelf, exp.Line())
 		g.compileExpression(is, exp.Right, scope, table)
-		is.define(Send, exp.Line(), exp.Operator, 1, nil, nil)
+		is.define(Send, exp.Line(), exp.Operator, 1, nil, nil, &CallSite{})
 	case "&":
 		g.compileExpression(is, exp.Right, scope, table)
 		is.define(SplatBlock, exp.Line())
@@ -303,11 +319,11 @@ func (g *Generator) compileInfixExpression(is *InstructionSet, node *ast.InfixEx
 	case "+", "-", ">", ">=", "<", "<=":
 		g.compileExpression(is, node.Left, scope, table)
 		g.compileExpression(is, node.Right, scope, table)
-		is.define(operatorMap[node.Operator], node.Line(), node.Operator)
+		is.define(operatorMap[node.Operator], node.Line(), node.Operator, &CallSite{})
 
 	default:
 		g.compileExpression(is, node.Left, scope, table)
 		g.compileExpression(is, node.Right, scope, table)
-		is.define(BinaryOperator, node.Line(), node.Operator)
+		is.define(BinaryOperator, node.Line(), node.Operator, &CallSite{})
 	}
 }
@@ -50,6 +50,23 @@ func (g *Generator) InitTopLevelScope(program *ast.Program) {
 	g.scope = &scope{program: program, localTable: newLocalTable(0), anchors: make(map[string]*anchor)}
 }
 
+// DeclareLocals declares the locals of the scopes the program is run in, from the
+// innermost scope outwards, in the order of their indices
+func (g *Generator) DeclareLocals(scopes [][]string) {
+	var table *localTable
+	for depth := range scopes {
+		upper := table
+		table = newLocalTable(depth)
+		table.upper = upper
+		for _, name := range scopes[len(scopes)-1-depth] {
+			table.set(name)
+		}
+	}
+	if table != nil {
+		g.scope.localTable = table
+	}
+}
+
 // GenerateInstructions returns compiled instructions
 func (g *Generator) GenerateInstructions(stmts []ast.Statement) []*InstructionSet {
 	g.compileStatements(stmts, g.scope, g.scope.localTable)
@@ -63,7 +80,13 @@ func (g *Generator) GenerateInstructions(stmts []ast.Statement) []*InstructionSe
 	g.instructionsWithAnchor = nil
 	// Perform some optimisations on the bytecode
 	for _, i := range g.instructionSets {
-		i.elide()
+		i.LocalsCaptured = i.locals != nil && i.locals.captured
+		if Optimise {
+			i.optimise()
+		} else {
+			i.elide()
+		}
+		i.markTailCalls()
 	}
 	return g.instructionSets
 }
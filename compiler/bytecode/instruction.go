@@ -3,6 +3,7 @@ package bytecode
 import (
 	"fmt"
 	"strings"
+	"sync/atomic"
 )
 
 // instruction set types
@@ -34,6 +35,7 @@ const (
 	PutFalse
 	PutString
 	PutFloat
+	PutRegexp
 	PutSelf
 	PutSuper
 	PutInt
@@ -68,6 +70,9 @@ const (
 	Dup
 	Defer
 	Leave
+	GetLocalAddInt
+	GetLocalSend
+	TailSend
 	InstructionCount
 )
 
@@ -86,6 +91,7 @@ var Instructions = [...]instruction{
 	PutFalse:             {"putfalse", 0, nil},
 	PutString:            {"putstring", 1, []bool{true}},
 	PutFloat:             {"putfloat", 1, []bool{true}},
+	PutRegexp:            {"putregexp", 1, []bool{true}},
 	PutSelf:              {"putself", 0, nil},
 	PutSuper:             {"putsuper", 0, nil},
 	PutInt:               {"putint", 1, []bool{false}},
@@ -105,14 +111,14 @@ var Instructions = [...]instruction{
 	DefMethod:            {"def_method", 3, []bool{false, true, true}},
 	DefMetaMethod:        {"def_meta_method", 3, []bool{false, true, true}},
 	DefClass:             {"def_class", 4, []bool{true, true, true, true}},
-	Send:                 {"send", 4, []bool{true, false, true, true}},
-	BinaryOperator:       {"bin_op", 1, []bool{true}},
-	Add:                  {"add", 1, []bool{true}},
-	Subtract:             {"subtract", 1, []bool{true}},
-	Greater:              {"greater", 1, []bool{true}},
-	Less:                 {"less", 1, []bool{true}},
-	GreaterEqual:         {"greater_equal", 1, []bool{true}},
-	LessEqual:            {"less_equal", 1, []bool{true}},
+	Send:                 {"send", 5, []bool{true, false, true, true, true}},
+	BinaryOperator:       {"bin_op", 2, []bool{true, true}},
+	Add:                  {"add", 2, []bool{true, true}},
+	Subtract:             {"subtract", 2, []bool{true, true}},
+	Greater:              {"greater", 2, []bool{true, true}},
+	Less:                 {"less", 2, []bool{true, true}},
+	GreaterEqual:         {"greater_equal", 2, []bool{true, true}},
+	LessEqual:            {"less_equal", 2, []bool{true, true}},
 	InvokeBlock:          {"invokeblock", 1, []bool{false}},
 	GetBlock:             {"getblock", 0, nil},
 	HasBlock:             {"hasblock", 0, nil},
@@ -120,6 +126,9 @@ var Instructions = [...]instruction{
 	Dup:                  {"dup", 0, nil},
 	Defer:                {"defer", 2, []bool{true, true}},
 	Leave:                {"leave", 0, nil},
+	GetLocalAddInt:       {"getlocal_add_int", 5, []bool{false, false, false, true, true}},
+	GetLocalSend:         {"getlocal_send", 7, []bool{false, false, true, false, true, true, true}},
+	TailSend:             {"tail_send", 5, []bool{true, false, true, true, true}},
 	InstructionCount:     {"instruction_count", 0, nil},
 }
 
@@ -129,6 +138,11 @@ type instruction struct {
 	objects    []bool
 }
 
+// ParamCount returns the number of operands that follow the opcode
+func ParamCount(opcode int) int {
+	return Instructions[opcode].paramCount
+}
+
 type anchorReference struct {
 	anchor   *anchor
 	insSet   *InstructionSet
@@ -142,6 +156,19 @@ func (a *anchor) define(l int) {
 	a.line = l
 }
 
+// CallSite is the last operand of each method call instruction.
+// The VM keeps its inline method cache for the call in it.
+type CallSite struct {
+	Cache atomic.Value
+	// SelfReceiver is set when the receiver is self, which can call private and protected methods
+	SelfReceiver bool
+}
+
+// String returns the name of the operand, for Inspect
+func (c *CallSite) String() string {
+	return "<call site>"
+}
+
 // InstructionSet contains a set of Instructions and some metadata
 type InstructionSet struct {
 	Name         string
@@ -152,12 +179,23 @@ type InstructionSet struct {
 	SourceMap    []int
 	Count        int
 	ArgTypes     ArgSet
+	// HasBlocks is set when the instructions create blocks, which may keep the frame alive
+	HasBlocks bool
+	// LocalsCaptured is set when blocks use the locals, which may happen from other threads
+	LocalsCaptured bool
+	// Compiled holds the instructions compiled by the VM, for engines which compile them
+	Compiled  atomic.Value
+	locals    *localTable
+	optimised bool
 }
 
 // ArgSet stores the metadata of a method definition's parameters.
 type ArgSet struct {
 	names []string
 	types []uint8
+	// classes are the names of the classes parameters are annotated with, or empty when they aren't
+	classes     []string
+	returnClass string
 }
 
 // Types are the getter method of *ArgSet's types attribute
@@ -188,6 +226,36 @@ func (as *ArgSet) setArg(index int, name string, argType uint8) {
 	as.types[index] = argType
 }
 
+// Classes returns the names of the classes the parameters are annotated with,
+// which is nil if none of them are
+func (as *ArgSet) Classes() []string {
+	return as.classes
+}
+
+// ReturnClass returns the name of the class the method is annotated to return, if any
+func (as *ArgSet) ReturnClass() string {
+	return as.returnClass
+}
+
+func (as *ArgSet) setClass(index int, class string) {
+	if as.classes == nil {
+		as.classes = make([]string, len(as.names))
+	}
+	as.classes[index] = class
+}
+
+// LocalNames returns the names of the instructions' own locals, in the order of their indices
+func (is *InstructionSet) LocalNames() []string {
+	if is.locals == nil {
+		return nil
+	}
+	names := make([]string, is.locals.count)
+	for name, i := range is.locals.store {
+		names[i] = name
+	}
+	return names
+}
+
 // Inspect returns a string representation of the InstructionSet
 func (is *InstructionSet) Inspect() string {
 	var out strings.Builder
@@ -280,6 +348,23 @@ func (is *InstructionSet) SetConstant(o interface{}) int {
 	return len(is.Constants) - 1
 }
 
+// markTailCalls turns the sends whose result the method returns into tail sends.
+// Methods which create blocks are left alone, as the blocks may use the frame,
+// and so are methods with a return type, which is checked when their frame returns.
+func (is *InstructionSet) markTailCalls() {
+	if is.Type != Method || is.HasBlocks || is.ArgTypes.returnClass != "" {
+		return
+	}
+	for i := 0; i < len(is.Instructions); {
+		op := is.Instructions[i]
+		next := i + 1 + Instructions[op].paramCount
+		if op == Send && (next >= len(is.Instructions) || is.Instructions[next] == Leave) {
+			is.Instructions[i] = TailSend
+		}
+		i = next
+	}
+}
+
 func (is *InstructionSet) elide() {
 	length := len(is.Instructions)
 	for i := 0; i < length; {
@@ -6,6 +6,8 @@ type localTable struct {
 	count int
 	depth int
 	upper *localTable
+	// captured is set when a block uses one of the locals
+	captured bool
 }
 
 func (lt *localTable) get(v string) (int, bool) {
@@ -39,6 +41,9 @@ func (lt *localTable) setLocal(v string, d int) (index, depth int) {
 func (lt *localTable) getLocal(v string, d int) (index, depth int, ok bool) {
 	index, ok = lt.get(v)
 	if ok {
+		if d > lt.depth {
+			lt.captured = true
+		}
 		return index, d - lt.depth, ok
 	}
 	if lt.upper != nil {
@@ -0,0 +1,278 @@
+package bytecode
+
+// Optimise controls whether generated instructions are optimised.
+// When it is off, jumps are still threaded.
+var Optimise = true
+
+// op is a decoded instruction. Jumps point at the op they jump to, which is nil
+// for the end of the instructions, so that ops can be removed and fused freely.
+type op struct {
+	code   int
+	params []int
+	line   int
+	target *op
+	// forward is the op that jumps to a removed op go to instead
+	forward *op
+	removed bool
+}
+
+func isJump(code int) bool {
+	return code == Jump || code == BranchIf || code == BranchUnless
+}
+
+// optimise runs the optimisation passes over the instructions.
+// Every pass keeps the source line of each instruction, and only combines
+// instructions from the same line.
+func (is *InstructionSet) optimise() {
+	if is.optimised || len(is.Instructions) == 0 {
+		return
+	}
+	is.optimised = true
+
+	ops := is.decode()
+	ops = peephole(ops)
+	ops = threadJumps(ops)
+	ops = removeUnreachable(ops)
+	is.encode(ops)
+}
+
+// decode splits the instructions into ops
+func (is *InstructionSet) decode() []*op {
+	var ops []*op
+	at := map[int]*op{}
+	for i := 0; i < len(is.Instructions); {
+		code := is.Instructions[i]
+		n := Instructions[code].paramCount
+		o := &op{code: code, params: append([]int(nil), is.Instructions[i+1:i+1+n]...), line: is.SourceMap[i]}
+		at[i] = o
+		ops = append(ops, o)
+		i += 1 + n
+	}
+	for _, o := range ops {
+		if isJump(o.code) {
+			o.target = at[o.params[0]]
+		}
+	}
+	return ops
+}
+
+// encode writes the ops back as instructions, with their source lines
+func (is *InstructionSet) encode(ops []*op) {
+	position := map[*op]int{}
+	n := 0
+	for _, o := range ops {
+		position[o] = n
+		n += 1 + len(o.params)
+	}
+
+	is.Instructions = make([]int, 0, n)
+	is.SourceMap = make([]int, 0, n)
+	for _, o := range ops {
+		if isJump(o.code) {
+			o.params[0] = n
+			if t := resolve(o.target); t != nil {
+				o.params[0] = position[t]
+			}
+		}
+		is.Instructions = append(is.Instructions, o.code)
+		is.Instructions = append(is.Instructions, o.params...)
+		for i := 0; i <= len(o.params); i++ {
+			is.SourceMap = append(is.SourceMap, o.line)
+		}
+	}
+	is.Count = n
+}
+
+// resolve follows removed ops to the op that replaced them
+func resolve(o *op) *op {
+	for o != nil && o.removed {
+		o = o.forward
+	}
+	return o
+}
+
+// jumpTargets returns the ops which are jumped to
+func jumpTargets(ops []*op) map[*op]bool {
+	targets := map[*op]bool{}
+	for _, o := range ops {
+		if isJump(o.code) {
+			targets[resolve(o.target)] = true
+		}
+	}
+	return targets
+}
+
+// pureOp returns true for instructions which only push a value, so can be
+// removed along with a following pop
+func pureOp(code int) bool {
+	switch code {
+	case PutTrue, PutFalse, PutNull, PutInt, PutString, PutFloat, PutSelf, GetLocal, Dup:
+		return true
+	}
+	return false
+}
+
+// peephole folds constant integer arithmetic and comparisons, removes values which
+// are pushed and immediately popped, and fuses common sequences into one instruction.
+// The ops after the first in each sequence must not be jumped to.
+func peephole(ops []*op) []*op {
+	targets := jumpTargets(ops)
+	var out []*op
+	// removed holds the ops taken out since the last op was added, which jumps
+	// go past to that next op
+	var removed []*op
+
+	remove := func(o *op) {
+		o.removed = true
+		removed = append(removed, o)
+	}
+	sameLine := func(seq ...*op) bool {
+		for _, o := range seq[1:] {
+			if o.line != seq[0].line || targets[o] {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, o := range ops {
+		for _, r := range removed {
+			r.forward = o
+		}
+		removed = nil
+		out = append(out, o)
+
+		for changed := true; changed; {
+			changed = false
+			n := len(out)
+			switch {
+			case n >= 3 && out[n-3].code == PutInt && out[n-2].code == PutInt && sameLine(out[n-3:]...):
+				a, b, operator := out[n-3], out[n-2], out[n-1]
+				if result, ok := foldInt(operator.code, a.params[0], b.params[0]); ok {
+					// The result takes the place of the first operand, which may be jumped to
+					switch v := result.(type) {
+					case int:
+						a.params[0] = v
+					case bool:
+						a.code, a.params = PutFalse, nil
+						if v {
+							a.code = PutTrue
+						}
+					}
+					remove(b)
+					remove(operator)
+					out = out[:n-2]
+					changed = true
+				}
+			case n >= 2 && out[n-1].code == Pop && pureOp(out[n-2].code) && sameLine(out[n-2:]...):
+				remove(out[n-2])
+				remove(out[n-1])
+				out = out[:n-2]
+				changed = true
+			case n >= 3 && out[n-3].code == GetLocal && out[n-2].code == PutInt && out[n-1].code == Add && sameLine(out[n-3:]...):
+				get, put, add := out[n-3], out[n-2], out[n-1]
+				get.code = GetLocalAddInt
+				get.params = append(append(get.params, put.params[0]), add.params...)
+				remove(put)
+				remove(add)
+				out = out[:n-2]
+			case n >= 2 && out[n-2].code == GetLocal && out[n-1].code == Send && out[n-1].params[1] == 0 && sameLine(out[n-2:]...):
+				get, send := out[n-2], out[n-1]
+				get.code = GetLocalSend
+				get.params = append(get.params, send.params...)
+				remove(send)
+				out = out[:n-1]
+			}
+		}
+	}
+	// Jumps to ops removed at the end go to the end
+	for _, r := range removed {
+		r.forward = nil
+	}
+	return out
+}
+
+// foldInt returns the result of the integer instruction on the constants,
+// or false if it can't be folded, such as when the result would overflow
+func foldInt(code, l, r int) (interface{}, bool) {
+	switch code {
+	case Add:
+		sum := l + r
+		return sum, (sum^l)&(sum^r) >= 0
+	case Subtract:
+		diff := l - r
+		return diff, (l^r)&(l^diff) >= 0
+	case Greater:
+		return l > r, true
+	case Less:
+		return l < r, true
+	case GreaterEqual:
+		return l >= r, true
+	case LessEqual:
+		return l <= r, true
+	}
+	return nil, false
+}
+
+// threadJumps makes jumps to jumps go straight to the final target, turns jumps
+// to the end or to a leave into a leave, and removes jumps to the next op
+func threadJumps(ops []*op) []*op {
+	var out []*op
+	for i, o := range ops {
+		if isJump(o.code) {
+			t := resolve(o.target)
+			for seen := 0; t != nil && t.code == Jump && seen < len(ops); seen++ {
+				t = resolve(t.target)
+			}
+			o.target = t
+
+			if o.code == Jump {
+				switch {
+				case t == nil || t.code == Leave:
+					o.code, o.params, o.target = Leave, nil, nil
+				case i+1 < len(ops) && t == ops[i+1]:
+					o.removed = true
+					o.forward = t
+					continue
+				}
+			}
+		}
+		out = append(out, o)
+	}
+	return out
+}
+
+// removeUnreachable removes the ops which can't be reached from the first op
+func removeUnreachable(ops []*op) []*op {
+	index := map[*op]int{}
+	for i, o := range ops {
+		index[o] = i
+	}
+
+	reached := make([]bool, len(ops))
+	work := []int{0}
+	for len(work) > 0 {
+		i := work[len(work)-1]
+		work = work[:len(work)-1]
+		for ; i < len(ops) && !reached[i]; i++ {
+			reached[i] = true
+			o := ops[i]
+			if isJump(o.code) {
+				if t := resolve(o.target); t != nil {
+					work = append(work, index[t])
+				}
+			}
+			if o.code == Jump || o.code == Leave {
+				break
+			}
+		}
+	}
+
+	var out []*op
+	for i, o := range ops {
+		if reached[i] {
+			out = append(out, o)
+		}
+	}
+	return out
+}
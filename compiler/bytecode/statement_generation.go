@@ -14,7 +14,7 @@ const (
 )
 
 func (g *Generator) compileStatements(stmts []ast.Statement, scope *scope, table *localTable) {
-	is := &InstructionSet{Type: Program, Name: Program}
+	is := &InstructionSet{Type: Program, Name: Program, locals: table}
 
 	for _, statement := range stmts {
 		g.compileStatement(is, statement, scope, table)
@@ -37,7 +37,7 @@ func (g *Generator) compileStatement(is *InstructionSet, statement ast.Statement
 			is.define(Pop, statement.Line())
 		}
 	case *ast.DefStatement:
-		g.compileDefStmt(is, stmt, scope)
+		g.compileDefStmt(is, stmt, scope, table)
 	case *ast.ClassStatement:
 		g.compileClassStmt(is, stmt, scope, table)
 		if stmt.SuperClass != nil {
@@ -117,7 +117,7 @@ func (g *Generator) compileClassStmt(is *InstructionSet, stmt *ast.ClassStatemen
 	scope = newScope()
 
 	// compile class's content
-	newIS := &InstructionSet{Name: stmt.Name.Value, Type: Class}
+	newIS := &InstructionSet{Name: stmt.Name.Value, Type: Class, locals: scope.localTable}
 
 	g.compileCodeBlock(newIS, stmt.Body, scope, scope.localTable)
 	newIS.define(Leave, stmt.Line())
@@ -138,7 +138,7 @@ func (g *Generator) compileClassStmt(is *InstructionSet, stmt *ast.ClassStatemen
 
 func (g *Generator) compileModuleStmt(is *InstructionSet, stmt *ast.ModuleStatement, scope *scope) {
 	scope = newScope()
-	newIS := &InstructionSet{Name: stmt.Name.Value, Type: Class}
+	newIS := &InstructionSet{Name: stmt.Name.Value, Type: Class, locals: scope.localTable}
 
 	g.compileCodeBlock(newIS, stmt.Body, scope, scope.localTable)
 	newIS.define(Leave, stmt.Line())
@@ -148,7 +148,7 @@ func (g *Generator) compileModuleStmt(is *InstructionSet, stmt *ast.ModuleStatem
 	is.define(Pop, stmt.Line())
 }
 
-func (g *Generator) compileDefStmt(is *InstructionSet, stmt *ast.DefStatement, scope *scope) {
+func (g *Generator) compileDefStmt(is *InstructionSet, stmt *ast.DefStatement, scope *scope, table *localTable) {
 	originalScope := scope
 	scope = newScope()
 
@@ -160,6 +160,7 @@ func (g *Generator) compileDefStmt(is *InstructionSet, stmt *ast.DefStatement, s
 			names: make([]string, len(stmt.Parameters)),
 			types: make([]uint8, len(stmt.Parameters)),
 		},
+		locals: scope.localTable,
 	}
 
 	for i, parameter := range stmt.Parameters {
@@ -168,6 +169,12 @@ func (g *Generator) compileDefStmt(is *InstructionSet, stmt *ast.DefStatement, s
 			scope.localTable.setLocal(exp.Value, scope.localTable.depth)
 
 			newIS.ArgTypes.setArg(i, exp.Value, NormalArg)
+		case *ast.TypedParameter:
+			scope.localTable.setLocal(exp.Name.Value, scope.localTable.depth)
+
+			class, _ := ast.ClassName(exp.Type)
+			newIS.ArgTypes.setArg(i, exp.Name.Value, NormalArg)
+			newIS.ArgTypes.setClass(i, class)
 		case *ast.AssignExpression:
 			exp.Optioned = 1
 
@@ -203,6 +210,10 @@ func (g *Generator) compileDefStmt(is *InstructionSet, stmt *ast.DefStatement, s
 		}
 	}
 
+	if stmt.ReturnType != nil {
+		newIS.ArgTypes.returnClass, _ = ast.ClassName(stmt.ReturnType)
+	}
+
 	if len(stmt.BlockStatement.Statements) == 0 {
 		newIS.define(PutNull, stmt.Line())
 	} else {
@@ -217,7 +228,7 @@ func (g *Generator) compileDefStmt(is *InstructionSet, stmt *ast.DefStatement, s
 		is.define(PutSelf, stmt.Line())
 		is.define(DefMethod, stmt.Line(), len(stmt.Parameters), stmt.Name.Value, newIS)
 	default:
-		g.compileExpression(is, stmt.Receiver, originalScope, originalScope.localTable)
+		g.compileExpression(is, stmt.Receiver, originalScope, table)
 		is.define(DefMetaMethod, stmt.Line(), len(stmt.Parameters), stmt.Name.Value, newIS)
 	}
 }
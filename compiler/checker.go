@@ -0,0 +1,281 @@
+package compiler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/robotii/lito/compiler/ast"
+	"github.com/robotii/lito/compiler/lexer"
+	"github.com/robotii/lito/compiler/parser"
+)
+
+// Problem is a type mismatch found by Check
+type Problem struct {
+	// Line is the line of the source the problem is on, counting from 1
+	Line    int
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%d: %s", p.Line, p.Message)
+}
+
+// Check parses the source code and reports the literal arguments and return values
+// which don't match the classes their methods are annotated with.
+// Only calls which can be to just one method defined in the source are checked.
+func Check(input string) ([]Problem, error) {
+	l := lexer.New(input)
+	p := parser.New(l, parser.NormalMode)
+	program, err := p.ParseProgram()
+	if err != nil {
+		return nil, fmt.Errorf(err.Message)
+	}
+
+	c := &checker{methods: map[string][]*ast.DefStatement{}, inits: map[string][]*ast.DefStatement{}}
+	c.collect(program.Statements, "")
+	c.statements(program.Statements)
+
+	sort.SliceStable(c.problems, func(i, j int) bool { return c.problems[i].Line < c.problems[j].Line })
+	return c.problems, nil
+}
+
+type checker struct {
+	// methods are the definitions of each method name
+	methods map[string][]*ast.DefStatement
+	// inits are the init methods of each class
+	inits    map[string][]*ast.DefStatement
+	problems []Problem
+}
+
+// collect finds the method definitions, along with the classes they are defined in
+func (c *checker) collect(statements []ast.Statement, class string) {
+	for _, s := range statements {
+		switch s := s.(type) {
+		case *ast.ClassStatement:
+			c.collect(s.Body.Statements, s.Name.Value)
+		case *ast.ModuleStatement:
+			c.collect(s.Body.Statements, s.Name.Value)
+		case *ast.DefStatement:
+			c.methods[s.Name.Value] = append(c.methods[s.Name.Value], s)
+			if s.Name.Value == "init" && s.Receiver == nil {
+				c.inits[class] = append(c.inits[class], s)
+			}
+		}
+	}
+}
+
+// literalClass returns the name of the class of a literal, or false if the expression isn't one
+func literalClass(exp ast.Expression) (string, bool) {
+	switch exp.(type) {
+	case *ast.IntegerLiteral:
+		return "Integer", true
+	case *ast.FloatLiteral:
+		return "Float", true
+	case *ast.StringLiteral:
+		return "String", true
+	case *ast.BooleanExpression:
+		return "Boolean", true
+	case *ast.NilExpression:
+		return "Nil", true
+	case *ast.ArrayExpression:
+		return "Array", true
+	case *ast.HashExpression:
+		return "Hash", true
+	case *ast.RangeExpression:
+		return "Range", true
+	case *ast.RegexpLiteral:
+		return "Regexp", true
+	}
+	return "", false
+}
+
+// literalClasses are the classes of literals
+var literalClasses = map[string]bool{
+	"Integer": true, "Float": true, "String": true, "Boolean": true, "Nil": true,
+	"Array": true, "Hash": true, "Range": true, "Regexp": true,
+}
+
+// mismatch returns the class of the literal, and true if it obviously isn't of the annotated class.
+// Only the classes of literals are compared, as other classes may be reopened or subclassed.
+func mismatch(exp ast.Expression, annotation string) (string, bool) {
+	class, ok := literalClass(exp)
+	if !ok || class == annotation {
+		return class, false
+	}
+	return class, literalClasses[annotation]
+}
+
+func (c *checker) report(line int, format string, args ...interface{}) {
+	c.problems = append(c.problems, Problem{Line: line + 1, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkCall checks the positional literal arguments of a call against the method's parameters
+func (c *checker) checkCall(call *ast.CallExpression, def *ast.DefStatement) {
+	for i, arg := range call.Arguments {
+		if i >= len(def.Parameters) {
+			return
+		}
+		switch arg := arg.(type) {
+		case *ast.ArgumentPairExpression:
+			continue
+		case *ast.PrefixExpression:
+			if arg.Operator == "*" {
+				return
+			}
+		}
+		param, ok := def.Parameters[i].(*ast.TypedParameter)
+		if !ok {
+			continue
+		}
+		annotation, _ := ast.ClassName(param.Type)
+		if class, ok := mismatch(arg, annotation); ok {
+			c.report(arg.Line(), "argument '%s' of method '%s' should be %s. got: %s", param.Name.Value, def.Name.Value, annotation, class)
+		}
+	}
+}
+
+// callee returns the only method the call can be to, or nil if there isn't exactly one.
+// Calls with a receiver other than self are only checked for the init method of a class.
+func (c *checker) callee(call *ast.CallExpression) *ast.DefStatement {
+	var defs []*ast.DefStatement
+	switch receiver := call.Receiver.(type) {
+	case nil:
+		defs = c.methods[call.Method]
+	case *ast.SelfExpression:
+		if receiver.IsSuper {
+			return nil
+		}
+		defs = c.methods[call.Method]
+	default:
+		class, ok := ast.ClassName(receiver)
+		if !ok || call.Method != "new" {
+			return nil
+		}
+		defs = c.inits[class[strings.LastIndex(class, ":")+1:]]
+	}
+	if len(defs) != 1 {
+		return nil
+	}
+	return defs[0]
+}
+
+// checkReturns checks the literal values a method annotated with a return type returns
+func (c *checker) checkReturns(def *ast.DefStatement) {
+	annotation, ok := ast.ClassName(def.ReturnType)
+	if !ok {
+		return
+	}
+	check := func(exp ast.Expression) {
+		if class, ok := mismatch(exp, annotation); ok {
+			c.report(exp.Line(), "method '%s' should return %s. got: %s", def.Name.Value, annotation, class)
+		}
+	}
+
+	statements := def.BlockStatement.Statements
+	if len(statements) > 0 {
+		if s, ok := statements[len(statements)-1].(*ast.ExpressionStatement); ok {
+			check(s.Expression)
+		}
+	}
+	var returns func(statements []ast.Statement)
+	returns = func(statements []ast.Statement) {
+		for _, s := range statements {
+			switch s := s.(type) {
+			case *ast.ReturnStatement:
+				if s.ReturnValue != nil {
+					check(s.ReturnValue)
+				}
+			case *ast.WhileStatement:
+				returns(s.Body.Statements)
+			case *ast.ExpressionStatement:
+				if i, ok := s.Expression.(*ast.IfExpression); ok {
+					for _, cond := range i.Conditionals {
+						returns(cond.Consequence.Statements)
+					}
+					if i.Alternative != nil {
+						returns(i.Alternative.Statements)
+					}
+				}
+			}
+		}
+	}
+	returns(statements)
+}
+
+func (c *checker) statements(statements []ast.Statement) {
+	for _, s := range statements {
+		c.statement(s)
+	}
+}
+
+func (c *checker) block(b *ast.BlockStatement) {
+	if b != nil {
+		c.statements(b.Statements)
+	}
+}
+
+func (c *checker) statement(s ast.Statement) {
+	switch s := s.(type) {
+	case *ast.ClassStatement:
+		c.block(s.Body)
+	case *ast.ModuleStatement:
+		c.block(s.Body)
+	case *ast.DefStatement:
+		c.checkReturns(s)
+		c.block(s.BlockStatement)
+	case *ast.ReturnStatement:
+		c.expression(s.ReturnValue)
+	case *ast.ExpressionStatement:
+		c.expression(s.Expression)
+	case *ast.WhileStatement:
+		c.expression(s.Condition)
+		c.block(s.Body)
+	case *ast.BlockStatement:
+		c.block(s)
+	}
+}
+
+func (c *checker) expression(exp ast.Expression) {
+	switch exp := exp.(type) {
+	case *ast.CallExpression:
+		if def := c.callee(exp); def != nil {
+			c.checkCall(exp, def)
+		}
+		c.expression(exp.Receiver)
+		for _, arg := range exp.Arguments {
+			c.expression(arg)
+		}
+		c.block(exp.Block)
+	case *ast.ArrayExpression:
+		for _, e := range exp.Elements {
+			c.expression(e)
+		}
+	case *ast.HashExpression:
+		for _, key := range exp.Keys {
+			c.expression(exp.Data[key])
+		}
+	case *ast.ArgumentPairExpression:
+		c.expression(exp.Value)
+	case *ast.PrefixExpression:
+		c.expression(exp.Right)
+	case *ast.InfixExpression:
+		c.expression(exp.Left)
+		c.expression(exp.Right)
+	case *ast.AssignExpression:
+		c.expression(exp.Value)
+	case *ast.IfExpression:
+		for _, cond := range exp.Conditionals {
+			c.expression(cond.Condition)
+			c.block(cond.Consequence)
+		}
+		c.block(exp.Alternative)
+	case *ast.YieldExpression:
+		for _, arg := range exp.Arguments {
+			c.expression(arg)
+		}
+	case *ast.RangeExpression:
+		c.expression(exp.Start)
+		c.expression(exp.End)
+	}
+}
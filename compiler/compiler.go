@@ -8,8 +8,9 @@ import (
 	"github.com/robotii/lito/compiler/parser"
 )
 
-// CompileToInstructions compiles input source code into instruction set data structures
-func CompileToInstructions(input string, pm parser.Mode) ([]*bytecode.InstructionSet, error) {
+// CompileToInstructions compiles input source code into instruction set data structures.
+// The locals of the scopes the code is run in can be given, from the innermost scope outwards.
+func CompileToInstructions(input string, pm parser.Mode, locals ...[]string) ([]*bytecode.InstructionSet, error) {
 	l := lexer.New(input)
 	p := parser.New(l, pm)
 	program, err := p.ParseProgram()
@@ -18,5 +19,6 @@ func CompileToInstructions(input string, pm parser.Mode) ([]*bytecode.Instructio
 	}
 	g := bytecode.NewGenerator()
 	g.InitTopLevelScope(program)
+	g.DeclareLocals(locals)
 	return g.GenerateInstructions(program.Statements), nil
 }
@@ -12,12 +12,17 @@ type Lexer interface {
 
 // mLexer structure used to convert programs into a stream of tokens.
 type mLexer struct {
-	input        []rune   // the current input buffer being lexed
-	position     int      // the index of the character we are currently lexing
-	readPosition int      // the index of the next character to be read
-	ch           rune     // the current character we are processing
-	line         int      // the line number we are on
-	fsm          *fsm.FSM // the finite state machine used to perform context-sensitive lexing
+	input        []rune          // the current input buffer being lexed
+	position     int             // the index of the character we are currently lexing
+	readPosition int             // the index of the next character to be read
+	ch           rune            // the current character we are processing
+	line         int             // the line number we are on
+	fsm          *fsm.FSM        // the finite state machine used to perform context-sensitive lexing
+	last         token.Token     // the last token, to tell a regexp from a division
+	locals       map[string]bool // names assigned to or bound as parameters, which may be locals
+	blockParams  bool            // whether the tokens are the parameters of a block, between '|'s
+	defining     bool            // whether the tokens follow def, up to the parameters
+	defParens    int             // the depth of parentheses in the parameters of a def
 }
 
 // FSM states
@@ -29,7 +34,7 @@ const (
 
 // New initialises a new lexer with input string
 func New(input string) Lexer {
-	l := &mLexer{input: []rune(input)}
+	l := &mLexer{input: []rune(input), locals: map[string]bool{}}
 	// Read the first character
 	l.advance()
 
@@ -45,6 +50,46 @@ func New(input string) Lexer {
 
 // NextToken lex and return the next token
 func (l *mLexer) NextToken() token.Token {
+	tok := l.nextToken()
+	l.noteLocal(tok)
+	l.last = tok
+	return tok
+}
+
+// noteLocal records the names which may be local variables: those assigned to,
+// and the parameters of blocks and methods. Scopes aren't followed, so a name
+// is kept from the point it is first seen.
+func (l *mLexer) noteLocal(tok token.Token) {
+	switch tok.Type {
+	case token.Assign, token.PlusEq, token.MinusEq, token.OrEq:
+		if l.last.Type == token.Ident {
+			l.locals[l.last.Literal] = true
+		}
+	case token.Bar:
+		l.blockParams = !l.blockParams && l.last.Type == token.LBrace
+	case token.Def:
+		l.defining = true
+	case token.LParen:
+		if l.defParens > 0 {
+			l.defParens++
+		} else if l.defining {
+			l.defParens = 1
+		}
+		l.defining = false
+	case token.RParen:
+		if l.defParens > 0 {
+			l.defParens--
+		}
+	case token.Ident:
+		if l.blockParams || l.defParens > 0 {
+			l.locals[tok.Literal] = true
+		}
+	case token.LBrace:
+		l.defining = false
+	}
+}
+
+func (l *mLexer) nextToken() token.Token {
 nextToken:
 	var tok token.Token
 	l.resetNosymbol()
@@ -97,6 +142,9 @@ nextToken:
 			tok = token.CreateOperator("!", l.line)
 		}
 	case '/':
+		if l.startsRegexp() {
+			return l.readRegexp()
+		}
 		tok = token.CreateOperator("/", l.line)
 	case '*':
 		if l.peek() == '*' {
@@ -112,6 +160,9 @@ nextToken:
 		} else if l.peek() == '-' {
 			l.advance()
 			tok = token.CreateOperator("<-", l.line)
+		} else if l.peek() == '<' {
+			l.advance()
+			tok = token.CreateOperator("<<", l.line)
 		} else {
 			tok = token.CreateOperator("<", l.line)
 		}
@@ -119,6 +170,9 @@ nextToken:
 		if l.peek() == '=' {
 			l.advance()
 			tok = token.CreateOperator(">=", l.line)
+		} else if l.peek() == '>' {
+			l.advance()
+			tok = token.CreateOperator(">>", l.line)
 		} else {
 			tok = token.CreateOperator(">", l.line)
 		}
@@ -175,6 +229,10 @@ nextToken:
 		}
 	case '%':
 		tok = token.CreateOperator("%", l.line)
+	case '^':
+		tok = token.CreateOperator("^", l.line)
+	case '~':
+		tok = token.CreateOperator("~", l.line)
 	case '#':
 		l.readComment()
 		goto nextToken
@@ -184,7 +242,11 @@ nextToken:
 			tok = token.CreateOperator("&&", l.line)
 			break
 		}
-		tok = token.CreateOperator("&", l.line)
+		if l.startsBlockPass() {
+			tok = token.CreateOperator("&", l.line)
+			break
+		}
+		tok = token.Create(token.BitAnd, "&", l.line)
 	case 0:
 		tok = token.Create(token.EOF, "", l.line)
 	default:
@@ -240,6 +302,8 @@ nextToken:
 			}
 			return token.Create(token.Illegal, string(l.ch), l.line)
 		} else if isDigit(l.ch) {
+			// The fractional part of a float follows a '.', so leave the method state
+			l.fsm.State(initial)
 			return token.Create(token.Int, l.readNumber(), l.line)
 		}
 
@@ -345,6 +409,90 @@ func (l *mLexer) readString(ch rune) string {
 	return result
 }
 
+// startsRegexp returns true if a '/' begins a regexp literal rather than being a division.
+// It is a division after anything that ends a value on the same line, or as a method
+// name, except that `foo /bar/` is taken to be a call to foo with a regexp.
+func (l *mLexer) startsRegexp() bool {
+	if l.last.Line != l.line {
+		return true
+	}
+	switch l.last.Type {
+	case token.Ident:
+		return l.callsWithArgument() && l.closesOnLine('/')
+	case token.Constant, token.InstanceVariable, token.Int, token.Float, token.String, token.Regexp,
+		token.RParen, token.RBracket, token.RBrace, token.True, token.False, token.Nil, token.Self,
+		token.Def, token.Dot:
+		return false
+	}
+	return true
+}
+
+// startsBlockPass returns true if a '&' passes a block, as in `tap &blk`, rather than being
+// the bitwise and operator. Like '/', it is an operator after anything that ends a value on
+// the same line, except that `foo &blk` is taken to be a call to foo passing blk.
+func (l *mLexer) startsBlockPass() bool {
+	if isWhitespace(l.peek()) {
+		return false
+	}
+	if l.last.Line == l.line && l.last.Type == token.Ident {
+		return l.callsWithArgument()
+	}
+	return l.startsRegexp()
+}
+
+// callsWithArgument returns true if the last token is taken to be a method called with an
+// argument starting at the current character, from a space before it and none after.
+// A name which may be a local is never taken to be a call, so `a /b` is a division.
+func (l *mLexer) callsWithArgument() bool {
+	return l.position > 0 && isWhitespace(l.input[l.position-1]) && !isWhitespace(l.peek()) && l.peek() != '=' &&
+		!l.locals[l.last.Literal]
+}
+
+// closesOnLine returns true if the character appears again, unescaped, before the end of the line
+func (l *mLexer) closesOnLine(ch rune) bool {
+	for i := l.readPosition; i < len(l.input) && l.input[i] != '\n'; i++ {
+		if isEscapeChar(l.input[i]) {
+			i++
+		} else if l.input[i] == ch {
+			return true
+		}
+	}
+	return false
+}
+
+// readRegexp reads a regexp literal such as /a+b/i. The flags are
+// turned into a prefix of the pattern, as in (?i)a+b.
+func (l *mLexer) readRegexp() token.Token {
+	line := l.line
+	l.advance()
+
+	var pattern []rune
+	for l.ch != '/' {
+		if l.ch == '\n' || l.ch == 0 {
+			return token.Create(token.Illegal, "/"+string(pattern), line)
+		}
+		if isEscapeChar(l.ch) && l.peek() == '/' {
+			l.advance()
+		} else if isEscapeChar(l.ch) {
+			pattern = append(pattern, l.ch)
+			l.advance()
+		}
+		pattern = append(pattern, l.ch)
+		l.advance()
+	}
+	l.advance()
+
+	var flags []rune
+	for isLetter(l.ch) {
+		flags = append(flags, l.ch)
+		l.advance()
+	}
+	if len(flags) > 0 {
+		pattern = append([]rune("(?"+string(flags)+")"), pattern...)
+	}
+	return token.Create(token.Regexp, string(pattern), line)
+}
+
 func (l *mLexer) readSymbol() string {
 	// Consume the ':' character
 	l.advance()
@@ -25,6 +25,7 @@ var Types = map[int]string{
 var Tokens = map[token.Type]bool{
 	token.Int:              true,
 	token.String:           true,
+	token.Regexp:           true,
 	token.True:             true,
 	token.False:            true,
 	token.Nil:              true,
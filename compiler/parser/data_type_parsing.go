@@ -56,32 +56,30 @@ func (p *Parser) parseNilExpression() ast.Expression {
 }
 
 func (p *Parser) parseHashExpression() ast.Expression {
-	return &ast.HashExpression{BaseNode: &ast.BaseNode{Token: p.curToken}, Data: p.parseHashPairs()}
+	exp := &ast.HashExpression{BaseNode: &ast.BaseNode{Token: p.curToken}, Data: map[string]ast.Expression{}}
+	p.parseHashPairs(exp)
+	return exp
 }
 
-func (p *Parser) parseHashPairs() map[string]ast.Expression {
-	pairs := map[string]ast.Expression{}
-
+func (p *Parser) parseHashPairs(exp *ast.HashExpression) {
 	if p.peekTokenIs(token.RBrace) {
 		p.nextToken()
-		return pairs
+		return
 	}
 
-	p.parseHashPair(pairs)
+	p.parseHashPair(exp)
 
 	for p.peekTokenIs(token.Comma) {
 		p.nextToken()
-		p.parseHashPair(pairs)
+		p.parseHashPair(exp)
 	}
 
 	if !p.expectPeek(token.RBrace) {
-		return nil
+		exp.Data, exp.Keys = nil, nil
 	}
-
-	return pairs
 }
 
-func (p *Parser) parseHashPair(pairs map[string]ast.Expression) {
+func (p *Parser) parseHashPair(exp *ast.HashExpression) {
 	var key string
 	var value ast.Expression
 
@@ -103,7 +101,14 @@ func (p *Parser) parseHashPair(pairs map[string]ast.Expression) {
 
 	p.nextToken()
 	value = p.parseExpression(precedence.Normal)
-	pairs[key] = value
+	if _, ok := exp.Data[key]; !ok {
+		exp.Keys = append(exp.Keys, key)
+	}
+	exp.Data[key] = value
+}
+
+func (p *Parser) parseRegexpLiteral() ast.Expression {
+	return &ast.RegexpLiteral{BaseNode: &ast.BaseNode{Token: p.curToken}, Value: p.curToken.Literal}
 }
 
 func (p *Parser) parseArrayExpression() ast.Expression {
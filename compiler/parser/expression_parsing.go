@@ -35,6 +35,7 @@ func (p *Parser) parseAssignExpression(v ast.Expression) ast.Expression {
 		exp.Variables = v.Variables
 	case *ast.CallExpression:
 		value = p.expandAssignmentValue(v)
+		p.fsm.State(oldState)
 		if v.Method == "[]" {
 			return &ast.CallExpression{
 				BaseNode:  &ast.BaseNode{},
@@ -322,6 +323,12 @@ func (p *Parser) parseArgumentPairExpression(key ast.Expression) ast.Expression
 
 		p.nextToken()
 		exp.Value = p.parseExpression(precedence.Normal)
+		// A class in place of a default value annotates the parameter's type
+		if _, ok := ast.ClassName(exp.Value); ok {
+			if name, ok := key.(*ast.Identifier); ok {
+				return &ast.TypedParameter{BaseNode: exp.BaseNode, Name: name, Type: exp.Value}
+			}
+		}
 		return exp
 	case states.ParsingFuncCall:
 		p.nextToken()
@@ -40,6 +40,7 @@ const (
 	REPLMode
 	TestMode
 	CommandLineMode
+	EvalMode
 )
 
 // New creates a new parser and returns it
@@ -53,7 +54,7 @@ func New(l lexer.Lexer, mode Mode) *Parser {
 	p.fsm = fsm.New(
 		states.Normal,
 		fsm.States{
-			{Name: states.ParsingFuncCall, From: []string{states.Normal}},
+			{Name: states.ParsingFuncCall, From: []string{states.Normal, states.ParsingAssignment}},
 			{Name: states.ParsingMethodParam, From: []string{states.Normal, states.ParsingAssignment}},
 			{Name: states.ParsingAssignment, From: []string{states.Normal, states.ParsingFuncCall}},
 			{Name: states.Normal, From: []string{states.ParsingFuncCall, states.ParsingMethodParam, states.ParsingAssignment}},
@@ -66,6 +67,7 @@ func New(l lexer.Lexer, mode Mode) *Parser {
 	p.registerPrefix(token.InstanceVariable, p.parseInstanceVariable)
 	p.registerPrefix(token.Int, p.parseIntegerLiteral)
 	p.registerPrefix(token.String, p.parseStringLiteral)
+	p.registerPrefix(token.Regexp, p.parseRegexpLiteral)
 	p.registerPrefix(token.True, p.parseBooleanLiteral)
 	p.registerPrefix(token.False, p.parseBooleanLiteral)
 	p.registerPrefix(token.Nil, p.parseNilExpression)
@@ -73,6 +75,7 @@ func New(l lexer.Lexer, mode Mode) *Parser {
 	p.registerPrefix(token.Plus, p.parsePrefixExpression)
 	p.registerPrefix(token.Asterisk, p.parsePrefixExpression)
 	p.registerPrefix(token.Bang, p.parsePrefixExpression)
+	p.registerPrefix(token.BitNot, p.parsePrefixExpression)
 	p.registerPrefix(token.Amp, p.parsePrefixExpression)
 	p.registerPrefix(token.LParen, p.parseGroupedExpression)
 	p.registerPrefix(token.If, p.parseIfExpression)
@@ -96,6 +99,11 @@ func New(l lexer.Lexer, mode Mode) *Parser {
 	p.registerInfix(token.Modulo, p.parseInfixExpression)
 	p.registerInfix(token.Slash, p.parseInfixExpression)
 	p.registerInfix(token.Pow, p.parseInfixExpression)
+	p.registerInfix(token.BitAnd, p.parseInfixExpression)
+	p.registerInfix(token.Bar, p.parseInfixExpression)
+	p.registerInfix(token.BitXor, p.parseInfixExpression)
+	p.registerInfix(token.LShift, p.parseInfixExpression)
+	p.registerInfix(token.RShift, p.parseInfixExpression)
 	p.registerInfix(token.Eq, p.parseInfixExpression)
 	p.registerInfix(token.NotEq, p.parseInfixExpression)
 	p.registerInfix(token.IsSame, p.parseInfixExpression)
@@ -125,6 +133,7 @@ func New(l lexer.Lexer, mode Mode) *Parser {
 	p.registerInfix(token.Finally, p.parseOperatorMethodCall)
 	p.registerInfix(token.Ident, p.parseOperatorMethodCall)
 	p.registerInfix(token.Class, p.parseOperatorMethodCall)
+	p.registerInfix(token.Yield, p.parseOperatorMethodCall)
 
 	return p
 }
@@ -165,7 +174,7 @@ func (p *Parser) ParseProgram() (program *ast.Program, err *errors.Error) {
 
 	// Set up last statement for testing, so we return the last value
 	// that was returned from executing the last statement
-	if p.Mode == TestMode || p.Mode == REPLMode {
+	if (p.Mode == TestMode || p.Mode == REPLMode || p.Mode == EvalMode) && len(program.Statements) > 0 {
 		stmt := program.Statements[len(program.Statements)-1]
 		expStmt, ok := stmt.(*ast.ExpressionStatement)
 		if ok {
@@ -12,6 +12,9 @@ const (
 	Range
 	Equals
 	Compare
+	BitOr
+	BitAnd
+	Shift
 	Sum
 	Product
 	BangPrefix
@@ -35,6 +38,11 @@ var LookupTable = map[token.Type]int{
 	token.Or:                 Logic,
 	token.Range:              Range,
 	token.RangeExcl:          Range,
+	token.Bar:                BitOr,
+	token.BitXor:             BitOr,
+	token.BitAnd:             BitAnd,
+	token.LShift:             Shift,
+	token.RShift:             Shift,
 	token.Plus:               Sum,
 	token.Minus:              Sum,
 	token.Modulo:             Sum,
@@ -52,6 +60,7 @@ var LookupTable = map[token.Type]int{
 	token.Finally:            Call,
 	token.Ident:              Call,
 	token.Class:              Call,
+	token.Yield:              Call,
 	token.Assign:             Assign,
 	token.PlusEq:             Assign,
 	token.MinusEq:            Assign,
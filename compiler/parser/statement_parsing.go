@@ -101,7 +101,8 @@ func (p *Parser) parseDefMethodStatement() *ast.DefStatement {
 			params = p.parseParameters()
 		}
 
-		if p.IsNotParamsToken() {
+		// The parameters may end with a type, which is a constant
+		if _, typed := lastParam(params).(*ast.TypedParameter); p.IsNotParamsToken() && !typed {
 			return nil
 		}
 
@@ -113,6 +114,20 @@ func (p *Parser) parseDefMethodStatement() *ast.DefStatement {
 	}
 
 	stmt.Parameters = params
+
+	if p.peekTokenIs(token.RightArrow) {
+		p.nextToken()
+		if !p.expectPeek(token.Constant) {
+			return nil
+		}
+		stmt.ReturnType = p.parseConstant()
+		if _, ok := ast.ClassName(stmt.ReturnType); !ok {
+			msg := fmt.Sprintf("Invalid return type: %s. Line: %d", stmt.ReturnType.String(), p.curToken.Line)
+			p.error = errors.InitError(msg, errors.MethodDefinitionError)
+			return nil
+		}
+	}
+
 	p.expectPeek(token.LBrace)
 	stmt.BlockStatement = p.parseBlockStatement(token.RBrace)
 	stmt.BlockStatement.KeepLastValue()
@@ -167,16 +182,16 @@ func (p *Parser) checkMethodParameters(params []ast.Expression) {
 
 	for _, param := range params {
 		switch exp := param.(type) {
-		case *ast.Identifier:
+		case *ast.Identifier, *ast.TypedParameter:
 			switch argState {
 			case arguments.OptionedArg:
-				p.error = errors.NewArgumentError(arguments.NormalArg, arguments.OptionedArg, exp.Value, p.curToken.Line)
+				p.error = errors.NewArgumentError(arguments.NormalArg, arguments.OptionedArg, getArgName(exp), p.curToken.Line)
 			case arguments.RequiredKeywordArg:
-				p.error = errors.NewArgumentError(arguments.NormalArg, arguments.RequiredKeywordArg, exp.Value, p.curToken.Line)
+				p.error = errors.NewArgumentError(arguments.NormalArg, arguments.RequiredKeywordArg, getArgName(exp), p.curToken.Line)
 			case arguments.OptionalKeywordArg:
-				p.error = errors.NewArgumentError(arguments.NormalArg, arguments.OptionalKeywordArg, exp.Value, p.curToken.Line)
+				p.error = errors.NewArgumentError(arguments.NormalArg, arguments.OptionalKeywordArg, getArgName(exp), p.curToken.Line)
 			case arguments.SplatArg:
-				p.error = errors.NewArgumentError(arguments.NormalArg, arguments.SplatArg, exp.Value, p.curToken.Line)
+				p.error = errors.NewArgumentError(arguments.NormalArg, arguments.SplatArg, getArgName(exp), p.curToken.Line)
 			}
 		case *ast.AssignExpression:
 			switch argState {
@@ -361,6 +376,13 @@ func (p *Parser) parseWhileStatement() *ast.WhileStatement {
 	return ws
 }
 
+func lastParam(params []ast.Expression) ast.Expression {
+	if len(params) == 0 {
+		return nil
+	}
+	return params[len(params)-1]
+}
+
 func paramDuplicated(params []ast.Expression, param ast.Expression) bool {
 	for _, p := range params {
 		if getArgName(param) == getArgName(p) {
@@ -21,6 +21,7 @@ const (
 	Int              = "INT"
 	Float            = "FLOAT"
 	String           = "STRING"
+	Regexp           = "REGEXP"
 	Comment          = "COMMENT"
 
 	Assign   = "="
@@ -37,6 +38,11 @@ const (
 	Or       = "||"
 	OrEq     = "||="
 	Modulo   = "%"
+	BitAnd   = "BIT_AND"
+	BitXor   = "^"
+	BitNot   = "~"
+	LShift   = "<<"
+	RShift   = ">>"
 
 	Match = "=~"
 	LT    = "<"
@@ -137,6 +143,10 @@ var operators = map[string]Type{
 	"||=": OrEq,
 	"%":   Modulo,
 	"&":   Amp,
+	"^":   BitXor,
+	"~":   BitNot,
+	"<<":  LShift,
+	">>":  RShift,
 
 	"=~": Match,
 	"<":  LT,
@@ -2,7 +2,11 @@ module github.com/robotii/lito
 
 go 1.20
 
-require github.com/chzyer/readline v0.0.0-20180603132655-2972be24d48e
+require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/chzyer/readline v0.0.0-20180603132655-2972be24d48e
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/chzyer/logex v1.1.10 // indirect
@@ -0,0 +1,16 @@
+#!/usr/bin/env bash
+# Runs the benchmarks, printing the time taken and memory allocated by each.
+# Any arguments are passed to lito, such as -mtype compiled.
+set -e
+cd "$(dirname "$0")/.."
+go build -o ./lito ./cmd/lito/
+profile=$(mktemp)
+trap 'rm -f "$profile"' EXIT
+
+for f in benchmarks/*.lito; do
+  start=$(date +%s%N)
+  ./lito -memprofile "$profile" "$@" "$f" > /dev/null
+  end=$(date +%s%N)
+  allocated=$(go tool pprof -sample_index=alloc_space -top -nodecount=1 "$profile" 2>/dev/null | sed -n 's/.* of \(.*\) total.*/\1/p')
+  printf "%-32s %8d ms %12s allocated\n" "$f" $(((end - start) / 1000000)) "$allocated"
+done
@@ -1,7 +1,9 @@
 #!/bin/sh
 set -e
 
-# Run the specs
-for file in ./specs/*.lito; do
-    ./lito $file
+# Run the specs with the default VM, without optimisations, and compiled
+for flags in "" "-noopt" "-mtype compiled"; do
+    for file in ./specs/*.lito; do
+        ./lito $flags $file
+    done
 done
@@ -189,28 +189,6 @@ var arrayInstanceMethods = []*BuiltinMethodObject{
 			return arr.Elements[indexValue]
 		},
 	},
-	{
-		Name: "any?",
-		Fn: func(receiver Object, t *Thread, args []Object) Object {
-			arr := receiver.(*ArrayObject)
-			blockFrame := t.GetBlock()
-			if blockFrame == nil {
-				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
-			}
-
-			if blockFrame.IsEmpty() {
-				return FALSE
-			}
-
-			for _, obj := range arr.Elements {
-				result := t.Yield(blockFrame, obj)
-				if result.IsTruthy() {
-					return TRUE
-				}
-			}
-			return FALSE
-		},
-	},
 	{
 		Name: "include?",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
@@ -259,65 +237,6 @@ var arrayInstanceMethods = []*BuiltinMethodObject{
 		},
 		Primitive: true,
 	},
-	{
-		Name: "count",
-		Fn: func(receiver Object, t *Thread, args []Object) Object {
-
-			aLen := len(args)
-			if aLen > 1 {
-				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, aLen)
-			}
-
-			arr := receiver.(*ArrayObject)
-			var count int
-			blockFrame := t.GetBlock()
-			if blockFrame == nil {
-				if aLen == 0 {
-					return IntegerObject(len(arr.Elements))
-				}
-
-				// TODO: This is a mess, clean it up
-				arg := args[0]
-				findInt, findIsInt := arg.(IntegerObject)
-				findString, findIsString := arg.(StringObject)
-				findBoolean, findIsBoolean := arg.(BooleanObject)
-
-				for i := 0; i < len(arr.Elements); i++ {
-					el := arr.Elements[i]
-					switch el := el.(type) {
-					case IntegerObject:
-						if findIsInt && findInt.equal(el) {
-							count++
-						}
-					case StringObject:
-						if findIsString && findString.equal(el) {
-							count++
-						}
-					case BooleanObject:
-						if findIsBoolean && findBoolean.equal(el) {
-							count++
-						}
-					}
-				}
-
-				return IntegerObject(count)
-			}
-
-			// Block was given
-			if blockFrame.IsEmpty() {
-				return IntegerObject(0)
-			}
-
-			for _, obj := range arr.Elements {
-				result := t.Yield(blockFrame, obj)
-				if result.IsTruthy() {
-					count++
-				}
-			}
-
-			return IntegerObject(count)
-		},
-	},
 	{
 		Name: "delete_at",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
@@ -353,6 +272,7 @@ var arrayInstanceMethods = []*BuiltinMethodObject{
 			copy(newArr, arr.Elements)
 			newObj := InitArrayObject(newArr)
 			newObj.SetVariables(arr.Variables().copy())
+			t.vm.dupSingletonClass(arr, newObj)
 			return newObj
 		},
 		Primitive: true,
@@ -467,27 +387,25 @@ var arrayInstanceMethods = []*BuiltinMethodObject{
 
 			a := receiver.(*ArrayObject)
 
-			hash := make(map[string]Object)
-			switch len(args) {
-			case 0:
-				for _, obj := range a.Elements {
-					hash[obj.ToString(t)] = t.Yield(blockFrame, obj)
+			if len(args) > 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
+			}
+
+			hash := newHashObject()
+			owners := map[string]Object{}
+			for _, obj := range a.Elements {
+				key, err := uniqueHashKey(t, owners, obj)
+				if err != nil {
+					return err
 				}
-			case 1:
-				arg := args[0]
-				for _, obj := range a.Elements {
-					switch b := t.Yield(blockFrame, obj); b.(type) {
-					case *NilObject:
-						hash[obj.ToString(t)] = arg
-					default:
-						hash[obj.ToString(t)] = b
-					}
+				value := t.Yield(blockFrame, obj)
+				if _, isNil := value.(*NilObject); isNil && len(args) == 1 {
+					value = args[0]
 				}
-			default:
-				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
+				hash.set(key, value)
 			}
 
-			return InitHashObject(hash)
+			return hash
 		},
 	},
 	{
@@ -603,43 +521,6 @@ var arrayInstanceMethods = []*BuiltinMethodObject{
 			return arr.push(args)
 		},
 	},
-	{
-		Name: "reduce",
-		Fn: func(receiver Object, t *Thread, args []Object) Object {
-			aLen := len(args)
-			if aLen > 1 {
-				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, aLen)
-			}
-			blockFrame := t.GetBlock()
-			if blockFrame == nil {
-				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
-			}
-
-			arr := receiver.(*ArrayObject)
-
-			if blockFrame.IsEmpty() {
-				return NIL
-			}
-
-			var prev Object
-			var start int
-			switch aLen {
-			case 0:
-				prev = arr.Elements[0]
-				start = 1
-			case 1:
-				prev = args[0]
-				start = 0
-			}
-
-			for i := start; i < len(arr.Elements); i++ {
-				result := t.Yield(blockFrame, prev, arr.Elements[i])
-				prev = result
-			}
-
-			return prev
-		},
-	},
 	{
 		Name: "reverse",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
@@ -733,7 +614,7 @@ var arrayInstanceMethods = []*BuiltinMethodObject{
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
 			ary := receiver.(*ArrayObject)
 
-			hash := make(map[string]Object)
+			hash := newHashObject()
 			for i, el := range ary.Elements {
 				kv, ok := el.(*ArrayObject)
 				if !ok {
@@ -749,10 +630,10 @@ var arrayInstanceMethods = []*BuiltinMethodObject{
 					return t.vm.InitErrorObject(t, errors.TypeError, "Expect the key in the Array's element #%d to be String. got: %s", i, k.Class().Name)
 				}
 
-				hash[k.ToString(t)] = kv.Elements[1]
+				hash.set(k.ToString(t), kv.Elements[1])
 			}
 
-			return InitHashObject(hash)
+			return hash
 		},
 	},
 	{
@@ -810,7 +691,8 @@ func InitArrayObject(elements []Object) *ArrayObject {
 func initArrayClass(vm *VM) *RClass {
 	arrayClass = vm.InitClass(classes.ArrayClass).
 		ClassMethods(arrayClassMethods).
-		InstanceMethods(arrayInstanceMethods)
+		InstanceMethods(arrayInstanceMethods).
+		include(enumerableModule)
 	return arrayClass
 }
 
@@ -960,7 +842,12 @@ func (a *ArrayObject) Swap(i, j int) {
 
 // Less is one of the required method to fulfill sortable interface
 func (a *ArrayObject) Less(i, j int) bool {
-	leftObj, rightObj := a.Elements[i], a.Elements[j]
+	return objectLessThan(a.Elements[i], a.Elements[j])
+}
+
+// objectLessThan compares two Numerics or two Strings.
+// Other objects are never less than one another.
+func objectLessThan(leftObj, rightObj Object) bool {
 	switch leftObj := leftObj.(type) {
 	case Numeric:
 		return leftObj.lessThan(rightObj)
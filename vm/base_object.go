@@ -4,6 +4,8 @@ package vm
 type BaseObj struct {
 	class             *RClass
 	instanceVariables Environment
+	// singleton holds the object's own methods, if it has any
+	singleton *RClass
 }
 
 // BaseObject returns a base obj with the given class
@@ -54,10 +56,13 @@ func (b *BaseObj) SetVariables(e Environment) {
 }
 
 // FindMethod returns the method with the corresponding name
+// The object's own methods are found first.
 func (b *BaseObj) FindMethod(methodName string, super bool) (method Object) {
 	class := b.class
 	if super {
 		class = class.superClass
+	} else if b.singleton != nil {
+		class = b.singleton
 	}
 	return class.lookupMethod(methodName)
 }
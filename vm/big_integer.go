@@ -0,0 +1,419 @@
+package vm
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+// BigIntegerObject represents an integer too large to fit in an Integer.
+// Integer arithmetic that overflows is promoted to a BigInteger, and results
+// that fit in an Integer again are demoted back.
+type BigIntegerObject struct {
+	BaseObj
+	value *big.Int
+}
+
+var bigIntClass *RClass
+
+var (
+	bigMinInt = big.NewInt(math.MinInt)
+	bigMaxInt = big.NewInt(math.MaxInt)
+)
+
+var bigIntegerClassMethods = []*BuiltinMethodObject{
+	{
+		Name:      "new",
+		Fn:        NoSuchMethod("new"),
+		Primitive: true,
+	},
+}
+
+var bigIntegerInstanceMethods = []*BuiltinMethodObject{
+	{
+		Name: "+",
+		Fn: bigIntegerArithmetic("+", func(l, r *big.Int) *big.Int {
+			return new(big.Int).Add(l, r)
+		}, func(l, r float64) float64 {
+			return l + r
+		}),
+		Primitive: true,
+	},
+	{
+		Name: "%",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return bigIntegerDivide(receiver, t, args, "%", (*big.Int).Rem, math.Mod)
+		},
+		Primitive: true,
+	},
+	{
+		Name: "-",
+		Fn: bigIntegerArithmetic("-", func(l, r *big.Int) *big.Int {
+			return new(big.Int).Sub(l, r)
+		}, func(l, r float64) float64 {
+			return l - r
+		}),
+		Primitive: true,
+	},
+	{
+		Name: "*",
+		Fn: bigIntegerArithmetic("*", func(l, r *big.Int) *big.Int {
+			return new(big.Int).Mul(l, r)
+		}, func(l, r float64) float64 {
+			return l * r
+		}),
+		Primitive: true,
+	},
+	{
+		Name: "**",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			b := receiver.(*BigIntegerObject)
+
+			switch right := args[0].(type) {
+			case IntegerObject:
+				if right < 0 {
+					return FloatObject(math.Pow(b.floatValue(), float64(right)))
+				}
+				return integerResult(new(big.Int).Exp(b.value, big.NewInt(int64(right)), nil))
+			case *BigIntegerObject:
+				return t.vm.InitErrorObject(t, errors.ArgumentError, "Exponent is too large: %s", right.ToString(t))
+			case FloatObject:
+				return FloatObject(math.Pow(b.floatValue(), float64(right)))
+			}
+			return coerceOperator(t, "**", receiver, args[0])
+		},
+		Primitive: true,
+	},
+	{
+		Name: "/",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return bigIntegerDivide(receiver, t, args, "/", (*big.Int).Quo, func(l, r float64) float64 {
+				return l / r
+			})
+		},
+		Primitive: true,
+	},
+	{
+		Name: ">",
+		Fn: bigIntegerCompare(">", func(c int) bool {
+			return c > 0
+		}),
+		Primitive: true,
+	},
+	{
+		Name: ">=",
+		Fn: bigIntegerCompare(">=", func(c int) bool {
+			return c >= 0
+		}),
+		Primitive: true,
+	},
+	{
+		Name: "<",
+		Fn: bigIntegerCompare("<", func(c int) bool {
+			return c < 0
+		}),
+		Primitive: true,
+	},
+	{
+		Name: "<=",
+		Fn: bigIntegerCompare("<=", func(c int) bool {
+			return c <= 0
+		}),
+		Primitive: true,
+	},
+	{
+		Name:      "&",
+		Fn:        bigIntegerBitwise((*big.Int).And),
+		Primitive: true,
+	},
+	{
+		Name:      "|",
+		Fn:        bigIntegerBitwise((*big.Int).Or),
+		Primitive: true,
+	},
+	{
+		Name:      "^",
+		Fn:        bigIntegerBitwise((*big.Int).Xor),
+		Primitive: true,
+	},
+	{
+		Name:      "<<",
+		Fn:        integerShift(true),
+		Primitive: true,
+	},
+	{
+		Name:      ">>",
+		Fn:        integerShift(false),
+		Primitive: true,
+	},
+	{
+		Name: "~",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return integerResult(new(big.Int).Not(receiver.(*BigIntegerObject).value))
+		},
+		Primitive: true,
+	},
+	{
+		Name: "float",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return FloatObject(receiver.(*BigIntegerObject).floatValue())
+		},
+		Primitive: true,
+	},
+	{
+		Name: "int",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return receiver
+		},
+		Primitive: true,
+	},
+	{
+		// Returns the integer as a string, in the given base from 2 to 36 if one is given
+		Name: "string",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) > 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
+			}
+			b := receiver.(*BigIntegerObject)
+			if len(args) == 0 {
+				return StringObject(b.value.String())
+			}
+
+			base, err := numericBase(t, args[0])
+			if err != nil {
+				return err
+			}
+			return StringObject(b.value.Text(base))
+		},
+		Primitive: true,
+	},
+	{
+		Name: "times",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, "Can't create a Range from %s", receiver.ToString(t))
+			}
+
+			n := receiver.(*BigIntegerObject).value
+			one := big.NewInt(1)
+			for i := new(big.Int); i.Cmp(n) < 0; i = new(big.Int).Add(i, one) {
+				t.Yield(blockFrame, integerResult(i))
+			}
+			return receiver
+		},
+	},
+	{
+		Name: "json",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return StringObject(receiver.ToJSON(t))
+		},
+	},
+}
+
+func initBigIntegerClass(vm *VM) *RClass {
+	bigIntClass = vm.InitClass(classes.BigIntegerClass).
+		inherits(intClass).
+		ClassMethods(bigIntegerClassMethods).
+		InstanceMethods(bigIntegerInstanceMethods)
+	return bigIntClass
+}
+
+// integerResult returns b as an Integer if it fits, or as a BigInteger otherwise
+func integerResult(b *big.Int) Object {
+	if b.Cmp(bigMinInt) >= 0 && b.Cmp(bigMaxInt) <= 0 {
+		return IntegerObject(int(b.Int64()))
+	}
+	return &BigIntegerObject{BaseObj: BaseObj{class: bigIntClass}, value: b}
+}
+
+// bigIntValue returns the value of an Integer or BigInteger as a big.Int
+func bigIntValue(obj Object) (*big.Int, bool) {
+	switch obj := obj.(type) {
+	case IntegerObject:
+		return big.NewInt(int64(obj)), true
+	case *BigIntegerObject:
+		return obj.value, true
+	}
+	return nil, false
+}
+
+// parseBigInteger parses s as an integer in the given base, promoting to a BigInteger when needed
+func parseBigInteger(s string, base int) (Object, bool) {
+	b, ok := new(big.Int).SetString(s, base)
+	if !ok {
+		return nil, false
+	}
+	return integerResult(b), true
+}
+
+// addInt adds two ints, returning false if the result overflows
+func addInt(l, r int) (int, bool) {
+	sum := l + r
+	return sum, (sum^l)&(sum^r) >= 0
+}
+
+// subInt subtracts two ints, returning false if the result overflows
+func subInt(l, r int) (int, bool) {
+	diff := l - r
+	return diff, (l^r)&(l^diff) >= 0
+}
+
+// mulInt multiplies two ints, returning false if the result overflows
+func mulInt(l, r int) (int, bool) {
+	if l == 0 || r == 0 {
+		return 0, true
+	}
+	product := l * r
+	if (l == -1 && r == math.MinInt) || (r == -1 && l == math.MinInt) {
+		return product, false
+	}
+	return product, product/r == l
+}
+
+// bigIntegerArithmetic returns a method applying the named operator to a BigInteger and a Numeric
+func bigIntegerArithmetic(name string, op func(l, r *big.Int) *big.Int, floatOp func(l, r float64) float64) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 1 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+		}
+		b := receiver.(*BigIntegerObject)
+
+		if right, ok := bigIntValue(args[0]); ok {
+			return integerResult(op(b.value, right))
+		}
+		if right, ok := args[0].(FloatObject); ok {
+			return FloatObject(floatOp(b.floatValue(), float64(right)))
+		}
+		return coerceOperator(t, name, receiver, args[0])
+	}
+}
+
+// bigIntegerDivide applies a division operator to a BigInteger and a Numeric,
+// raising a ZeroDivisionError when dividing by zero
+func bigIntegerDivide(receiver Object, t *Thread, args []Object, name string, op func(z, l, r *big.Int) *big.Int, floatOp func(l, r float64) float64) Object {
+	if len(args) != 1 {
+		return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+	}
+	b := receiver.(*BigIntegerObject)
+
+	if right, ok := bigIntValue(args[0]); ok {
+		if right.Sign() == 0 {
+			return t.vm.InitErrorObject(t, errors.ZeroDivisionError, errors.DividedByZero)
+		}
+		return integerResult(op(new(big.Int), b.value, right))
+	}
+	if right, ok := args[0].(FloatObject); ok {
+		if right == 0 {
+			return t.vm.InitErrorObject(t, errors.ZeroDivisionError, errors.DividedByZero)
+		}
+		return FloatObject(floatOp(b.floatValue(), float64(right)))
+	}
+	return coerceOperator(t, name, receiver, args[0])
+}
+
+// bigIntegerCompare returns a method comparing a BigInteger with a Numeric
+func bigIntegerCompare(name string, test func(c int) bool) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 1 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+		}
+		c, ok := receiver.(*BigIntegerObject).compare(args[0])
+		if !ok {
+			return coerceOperator(t, name, receiver, args[0])
+		}
+		return BooleanObject(test(c))
+	}
+}
+
+// bigIntegerBitwise returns a method applying a bitwise operator to a BigInteger and an Integer
+func bigIntegerBitwise(op func(z, l, r *big.Int) *big.Int) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 1 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+		}
+		right, ok := bigIntValue(args[0])
+		if !ok {
+			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.IntegerClass, args[0].Class().Name)
+		}
+		return integerResult(op(new(big.Int), receiver.(*BigIntegerObject).value, right))
+	}
+}
+
+// compare returns -1, 0 or 1 as b is less than, equal to or greater than the Numeric.
+// It returns false if the object is not Numeric.
+func (b *BigIntegerObject) compare(obj Object) (int, bool) {
+	if right, ok := bigIntValue(obj); ok {
+		return b.value.Cmp(right), true
+	}
+	if right, ok := obj.(FloatObject); ok {
+		return new(big.Float).SetInt(b.value).Cmp(big.NewFloat(float64(right))), true
+	}
+	return 0, false
+}
+
+// Value returns the big.Int
+func (b *BigIntegerObject) Value() interface{} {
+	return b.value
+}
+
+// Numeric interface
+func (b *BigIntegerObject) floatValue() float64 {
+	f, _ := new(big.Float).SetInt(b.value).Float64()
+	return f
+}
+
+func (b *BigIntegerObject) lessThan(arg Object) bool {
+	c, ok := b.compare(arg)
+	return ok && c < 0
+}
+
+// EqualTo returns true if the object is a Numeric with the same value
+func (b *BigIntegerObject) EqualTo(with Object) bool {
+	switch with.(type) {
+	case *DecimalObject, *RationalObject:
+		return with.EqualTo(b)
+	}
+	c, ok := b.compare(with)
+	return ok && c == 0
+}
+
+// ToString returns the integer in base 10
+func (b *BigIntegerObject) ToString(t *Thread) string {
+	return b.value.String()
+}
+
+// Inspect delegates to ToString
+func (b *BigIntegerObject) Inspect(t *Thread) string {
+	return b.ToString(t)
+}
+
+// ToJSON just delegates to ToString
+func (b *BigIntegerObject) ToJSON(t *Thread) string {
+	return b.ToString(t)
+}
+
+// IsTruthy returns true, as a BigInteger is never zero
+func (b *BigIntegerObject) IsTruthy() bool {
+	return true
+}
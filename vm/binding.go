@@ -0,0 +1,224 @@
+package vm
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/robotii/lito/compiler"
+	"github.com/robotii/lito/compiler/parser"
+	"github.com/robotii/lito/compiler/token"
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+// evalFilename is the file name errors in code run by eval are reported in
+const evalFilename = "(eval)"
+
+// BindingObject gives access to the locals of the frame it was made in,
+// and runs code which can use them
+type BindingObject struct {
+	BaseObj
+	// frame holds the locals defined by the code the binding runs.
+	// Its ep is the frame the binding was made in.
+	frame *CallFrame
+	// names are the names of the frame's locals, in the order of their indices
+	names []string
+}
+
+var bindingClass *RClass
+
+var bindingInstanceMethods = []*BuiltinMethodObject{
+	{
+		// Runs the code with the binding's locals, and returns the value of the last statement
+		Name: "eval",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			code, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+			}
+			return receiver.(*BindingObject).eval(t, string(code))
+		},
+	},
+	{
+		Name: "local_get",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			name, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+			}
+			b := receiver.(*BindingObject)
+			index, depth, ok := b.lookup(string(name))
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.NameError, errors.UndefinedLocal, string(name))
+			}
+			return b.frame.localValue(index, depth)
+		},
+		Primitive: true,
+	},
+	{
+		// Sets the local, which is defined in the binding if there isn't one of that name
+		Name: "local_set",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 2 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 2, len(args))
+			}
+			name, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+			}
+			if !templateLocal.MatchString(string(name)) || token.LookupIdent(string(name)) != token.Ident {
+				return t.vm.InitErrorObject(t, errors.NameError, errors.InvalidLocalName, string(name))
+			}
+			b := receiver.(*BindingObject)
+			index, depth, ok := b.lookup(string(name))
+			if !ok {
+				b.names = append(b.names, string(name))
+				index, depth = len(b.names)-1, 0
+			}
+			b.frame.insertLocal(index, depth, args[1])
+			return args[1]
+		},
+		Primitive: true,
+	},
+	{
+		// Returns the names of the locals, innermost first
+		Name: "local_names",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			seen := map[string]bool{}
+			var names []Object
+			for _, scope := range receiver.(*BindingObject).scopes() {
+				for _, name := range scope {
+					if !seen[name] {
+						seen[name] = true
+						names = append(names, StringObject(name))
+					}
+				}
+			}
+			return InitArrayObject(names)
+		},
+		Primitive: true,
+	},
+}
+
+func initBindingClass(vm *VM) *RClass {
+	bindingClass = vm.InitClass(classes.BindingClass).
+		ClassMethods([]*BuiltinMethodObject{{Name: "new", Fn: NoSuchMethod("new"), Primitive: true}}).
+		InstanceMethods(bindingInstanceMethods)
+	return bindingClass
+}
+
+// newBindingObject returns a binding of the frame's locals.
+// The frame is no longer reused once it returns, as the binding may outlive it.
+func newBindingObject(cf *CallFrame) *BindingObject {
+	cf.bound = true
+	// The block given to the method the frame belongs to
+	blockFrame := cf.blockFrame
+	if cf.isBlock && blockFrame != nil && cf.ep != nil && blockFrame.ep == cf.ep {
+		blockFrame = cf.ep.blockFrame
+	}
+	return &BindingObject{
+		BaseObj: BaseObj{class: bindingClass},
+		frame: &CallFrame{
+			baseFrame: baseFrame{self: cf.self, blockFrame: blockFrame, fileName: evalFilename},
+			ep:        cf,
+		},
+	}
+}
+
+// callerFrame returns the frame of the code which called the builtin method being run
+func (t *Thread) callerFrame() *CallFrame {
+	for i := t.callFrameStack.pointer - 1; i >= 0; i-- {
+		if cf, ok := t.callFrameStack.callFrames[i].(*CallFrame); ok && cf.native == nil {
+			return cf
+		}
+	}
+	return nil
+}
+
+// scopes returns the names of the locals of the binding, and of each frame it
+// can see, innermost first
+func (b *BindingObject) scopes() [][]string {
+	scopes := [][]string{b.names}
+	for cf := b.frame.ep; cf != nil; cf = cf.ep {
+		scopes = append(scopes, cf.instructionSet.LocalNames())
+	}
+	return scopes
+}
+
+// lookup returns the index and depth of the named local
+func (b *BindingObject) lookup(name string) (index, depth int, ok bool) {
+	for depth, scope := range b.scopes() {
+		for index, n := range scope {
+			if n == name {
+				return index, depth, true
+			}
+		}
+	}
+	return -1, 0, false
+}
+
+// eval runs the code with the binding's locals, and returns the value of the last statement.
+// The locals the code defines are kept by the binding.
+func (b *BindingObject) eval(t *Thread, code string) Object {
+	sets, err := compiler.CompileToInstructions(code, parser.EvalMode, b.scopes()...)
+	if err != nil {
+		msg := err.Error()
+		if m := compileErrorLine.FindStringSubmatch(msg); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			msg = strings.TrimSpace(strings.Replace(msg, m[0], "", 1))
+			return t.vm.InitErrorObject(t, errors.SyntaxError, "%s:%d: %s", evalFilename, line, msg)
+		}
+		return t.vm.InitErrorObject(t, errors.SyntaxError, "%s: %s", evalFilename, msg)
+	}
+	program := t.vm.transferProgram(evalFilename, sets)
+	if program == nil {
+		return NIL
+	}
+	b.names = program.LocalNames()
+
+	// The code is run with the binding's locals, as the REPL does with the previous line's
+	cf := newNormalCallFrame(program, evalFilename, 1)
+	cf.locals = b.frame.locals
+	cf.ep = b.frame.ep
+	cf.self = b.frame.self
+	cf.blockFrame = b.frame.blockFrame
+	defer func() {
+		b.frame.locals = cf.locals
+	}()
+
+	pointer := t.Stack.pointer
+	t.evaluateNormalFrame(cf)
+	if t.Stack.pointer > pointer {
+		return t.Stack.top()
+	}
+	return NIL
+}
+
+// ToString returns the name of the class
+func (b *BindingObject) ToString(t *Thread) string {
+	return "#<Binding>"
+}
+
+// Inspect delegates to ToString
+func (b *BindingObject) Inspect(t *Thread) string {
+	return b.ToString(t)
+}
+
+// ToJSON delegates to ToString
+func (b *BindingObject) ToJSON(t *Thread) string {
+	return b.ToString(t)
+}
+
+// EqualTo returns true if the objects are the same binding
+func (b *BindingObject) EqualTo(with Object) bool {
+	return b == with
+}
@@ -15,6 +15,7 @@ type BlockObject struct {
 	ep             *CallFrame
 	self           Object
 	splat          bool
+	native         func([]Object) Object
 }
 
 var blockClassMethods = []*BuiltinMethodObject{
@@ -25,7 +26,7 @@ var blockClassMethods = []*BuiltinMethodObject{
 			if blockFrame == nil {
 				return t.vm.InitErrorObject(t, errors.ArgumentError, "Can't create block object without block argument")
 			}
-			return initBlockObject(t.vm, blockFrame.instructionSet, blockFrame.ep, blockFrame.self)
+			return blockFrame.blockObject(t.vm, blockFrame.self)
 		},
 	},
 }
@@ -58,11 +59,19 @@ func initBlockObject(vm *VM, is *bytecode.InstructionSet, ep *CallFrame, self Ob
 	}
 }
 
+// blockObject returns a Block object for the block frame, with the given self
+func (cf *CallFrame) blockObject(vm *VM, self Object) *BlockObject {
+	bo := initBlockObject(vm, cf.instructionSet, cf.ep, self)
+	bo.native = cf.native
+	return bo
+}
+
 func (bo *BlockObject) asCallFrame(t *Thread) *CallFrame {
 	c := newNormalCallFrame(bo.instructionSet, bo.instructionSet.Filename, bo.instructionSet.SourceMap[0])
 	c.ep = bo.ep
 	c.self = bo.self
 	c.isBlock = true
+	c.native = bo.native
 	return c
 }
 
@@ -93,5 +102,6 @@ func (bo *BlockObject) copy() Object {
 		instructionSet: bo.instructionSet,
 		ep:             bo.ep,
 		self:           bo.self,
+		native:         bo.native,
 	}
 }
@@ -0,0 +1,562 @@
+package vm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+// BytesObject represents a sequence of raw bytes, such as binary data or a digest.
+// Unlike a String, it is indexed by byte rather than by character.
+type BytesObject struct {
+	BaseObj
+	bytes []byte
+}
+
+// packItem is a single code in a pack format, along with its repeat count
+type packItem struct {
+	code  byte
+	count int
+}
+
+// packSizes gives the number of bytes used by each of the fixed size pack codes
+var packSizes = map[byte]int{
+	'x': 1, '?': 1, 'b': 1, 'B': 1,
+	'h': 2, 'H': 2,
+	'i': 4, 'I': 4, 'f': 4,
+	'q': 8, 'Q': 8, 'd': 8,
+}
+
+var bytesClassMethods = []*BuiltinMethodObject{
+	{
+		// Creates Bytes from a String, another Bytes, or an Array of Integers from 0 to 255.
+		Name: "new",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) > 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
+			}
+			if len(args) == 0 {
+				return t.vm.InitBytesObject(nil)
+			}
+
+			if arr, ok := args[0].(*ArrayObject); ok {
+				b := make([]byte, len(arr.Elements))
+				for i, elem := range arr.Elements {
+					n, ok := elem.(IntegerObject)
+					if !ok || n < 0 || n > math.MaxUint8 {
+						return t.vm.InitErrorObject(t, errors.ArgumentError, "Expect Array elements to be Integers from 0 to 255. got: %s", elem.Inspect(t))
+					}
+					b[i] = byte(n)
+				}
+				return t.vm.InitBytesObject(b)
+			}
+
+			b, ok := bytesValue(args[0])
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "String, Bytes or Array", args[0].Class().Name)
+			}
+			return t.vm.InitBytesObject(append([]byte(nil), b...))
+		},
+	},
+	{
+		Name:      "from_hex",
+		Fn:        decodeMethod("hex", hex.DecodeString),
+		Primitive: true,
+	},
+	{
+		Name:      "from_base64",
+		Fn:        decodeMethod("base64", base64.StdEncoding.DecodeString),
+		Primitive: true,
+	},
+	{
+		// Packs the values into Bytes according to the format, which works like Python's struct module.
+		// The format may start with `<` for little endian, the default, or `>` or `!` for big endian.
+		// The codes are b/B, h/H, i/I and q/Q for signed/unsigned 8, 16, 32 and 64 bit integers,
+		// f and d for floats, ? for booleans and x for a zero byte. Each may be preceded by a count.
+		// `s` packs a String or Bytes, with the count giving its length, padded with zero bytes.
+		Name: "pack",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) < 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentMore, 1, len(args))
+			}
+
+			format, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 1, classes.StringClass, args[0].Class().Name)
+			}
+			order, items, err := parsePackFormat(string(format))
+			if err != nil {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, err.Error())
+			}
+
+			values := args[1:]
+			var buf bytes.Buffer
+			for _, item := range items {
+				if item.code == 'x' {
+					buf.Write(make([]byte, item.count))
+					continue
+				}
+
+				repeat := item.count
+				if item.code == 's' {
+					repeat = 1
+				}
+				for i := 0; i < repeat; i++ {
+					if len(values) == 0 {
+						return t.vm.InitErrorObject(t, errors.ArgumentError, "Not enough values for pack format %s", format.Inspect(t))
+					}
+					if err := packValue(&buf, order, item, values[0]); err != nil {
+						return t.vm.InitErrorObject(t, errors.TypeError, "Can't pack %s with '%c': %s", values[0].Inspect(t), item.code, err.Error())
+					}
+					values = values[1:]
+				}
+			}
+			if len(values) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, "Too many values for pack format %s", format.Inspect(t))
+			}
+			return t.vm.InitBytesObject(buf.Bytes())
+		},
+	},
+}
+
+var bytesInstanceMethods = []*BuiltinMethodObject{
+	{
+		// Returns the byte at the given index as an Integer, or a slice of the Bytes
+		// when given a Range, or a start index and length.
+		// Negative indexes count back from the end.
+		Name: "[]",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			b := receiver.(*BytesObject).bytes
+			switch len(args) {
+			case 1:
+				switch arg := args[0].(type) {
+				case IntegerObject:
+					i, ok := normaliseBytesIndex(len(b), int(arg))
+					if !ok || i == len(b) {
+						return NIL
+					}
+					return IntegerObject(b[i])
+				case *RangeObject:
+					start, startOk := normaliseBytesIndex(len(b), arg.Start)
+					end, endOk := normaliseBytesIndex(len(b), arg.End)
+					if !arg.Exclusive {
+						end++
+					}
+					if !startOk || !endOk || end > len(b) || start > end {
+						return NIL
+					}
+					return t.vm.InitBytesObject(b[start:end:end])
+				default:
+					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Integer or Range", args[0].Class().Name)
+				}
+			case 2:
+				start, ok := args[0].(IntegerObject)
+				if !ok {
+					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 1, classes.IntegerClass, args[0].Class().Name)
+				}
+				count, ok := args[1].(IntegerObject)
+				if !ok {
+					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 2, classes.IntegerClass, args[1].Class().Name)
+				}
+				if count < 0 {
+					return t.vm.InitErrorObject(t, errors.ArgumentError, errors.NegativeSecondValue, int(count))
+				}
+				i, ok := normaliseBytesIndex(len(b), int(start))
+				if !ok {
+					return NIL
+				}
+				end := i + int(count)
+				if end > len(b) {
+					end = len(b)
+				}
+				return t.vm.InitBytesObject(b[i:end:end])
+			default:
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentRange, 1, 2, len(args))
+			}
+		},
+		Primitive: true,
+	},
+	{
+		// Returns new Bytes with the given Bytes or String appended.
+		Name: "+",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			other, ok := bytesValue(args[0])
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "String or Bytes", args[0].Class().Name)
+			}
+
+			b := receiver.(*BytesObject).bytes
+			result := make([]byte, 0, len(b)+len(other))
+			return t.vm.InitBytesObject(append(append(result, b...), other...))
+		},
+		Primitive: true,
+	},
+	{
+		Name: "base64",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return StringObject(base64.StdEncoding.EncodeToString(receiver.(*BytesObject).bytes))
+		},
+		Primitive: true,
+	},
+	{
+		// Yields each byte as an Integer.
+		Name: "each",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			if blockFrame.IsEmpty() {
+				return receiver
+			}
+			for _, c := range receiver.(*BytesObject).bytes {
+				t.Yield(blockFrame, IntegerObject(c))
+				if blockFrame.IsRemoved() {
+					break
+				}
+			}
+			return receiver
+		},
+	},
+	{
+		Name: "empty?",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return BooleanObject(len(receiver.(*BytesObject).bytes) == 0)
+		},
+		Primitive: true,
+	},
+	{
+		Name: "hex",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return StringObject(hex.EncodeToString(receiver.(*BytesObject).bytes))
+		},
+		Primitive: true,
+	},
+	{
+		Name:      "length",
+		Fn:        bytesLength,
+		Primitive: true,
+	},
+	{
+		Name:      "size",
+		Fn:        bytesLength,
+		Primitive: true,
+	},
+	{
+		// Converts the Bytes to a String, raising an ArgumentError if they are not valid UTF-8.
+		Name: "string",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+
+			b := receiver.(*BytesObject).bytes
+			if !utf8.Valid(b) {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, "Bytes are not valid UTF-8: %s", receiver.Inspect(t))
+			}
+			return StringObject(b)
+		},
+		Primitive: true,
+	},
+	{
+		Name: "to_a",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+
+			b := receiver.(*BytesObject).bytes
+			elems := make([]Object, len(b))
+			for i, c := range b {
+				elems[i] = IntegerObject(c)
+			}
+			return InitArrayObject(elems)
+		},
+		Primitive: true,
+	},
+	{
+		// Unpacks the Bytes into an Array of values, using the same format as `Bytes pack`.
+		// Trailing bytes which are not covered by the format are ignored.
+		Name: "unpack",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			format, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+			}
+			order, items, err := parsePackFormat(string(format))
+			if err != nil {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, err.Error())
+			}
+
+			b := receiver.(*BytesObject).bytes
+			var values []Object
+			for _, item := range items {
+				if item.code == 's' {
+					if len(b) < item.count {
+						return t.vm.InitErrorObject(t, errors.ArgumentError, "Not enough bytes for unpack format %s", format.Inspect(t))
+					}
+					values = append(values, t.vm.InitBytesObject(append([]byte(nil), b[:item.count]...)))
+					b = b[item.count:]
+					continue
+				}
+
+				size := packSizes[item.code]
+				for i := 0; i < item.count; i++ {
+					if len(b) < size {
+						return t.vm.InitErrorObject(t, errors.ArgumentError, "Not enough bytes for unpack format %s", format.Inspect(t))
+					}
+					if item.code != 'x' {
+						values = append(values, unpackValue(order, item.code, b[:size]))
+					}
+					b = b[size:]
+				}
+			}
+			return InitArrayObject(values)
+		},
+		Primitive: true,
+	},
+	{
+		Name: "utf8?",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return BooleanObject(utf8.Valid(receiver.(*BytesObject).bytes))
+		},
+		Primitive: true,
+	},
+}
+
+// InitBytesObject returns a Bytes object holding the given bytes
+func (vm *VM) InitBytesObject(b []byte) *BytesObject {
+	return &BytesObject{
+		BaseObj: BaseObj{class: vm.TopLevelClass(classes.BytesClass)},
+		bytes:   b,
+	}
+}
+
+func initBytesClass(vm *VM) *RClass {
+	return vm.InitClass(classes.BytesClass).
+		ClassMethods(bytesClassMethods).
+		InstanceMethods(bytesInstanceMethods)
+}
+
+// bytesValue returns the bytes of a Bytes or String argument
+func bytesValue(obj Object) ([]byte, bool) {
+	switch obj := obj.(type) {
+	case *BytesObject:
+		return obj.bytes, true
+	case StringObject:
+		return []byte(obj), true
+	}
+	return nil, false
+}
+
+func bytesLength(receiver Object, t *Thread, args []Object) Object {
+	if len(args) != 0 {
+		return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+	}
+	return IntegerObject(len(receiver.(*BytesObject).bytes))
+}
+
+// normaliseBytesIndex converts a negative index into one from the start,
+// returning false if the index is outside of the bytes
+func normaliseBytesIndex(length, i int) (int, bool) {
+	if i < 0 {
+		i += length
+	}
+	return i, i >= 0 && i <= length
+}
+
+func parsePackFormat(format string) (binary.ByteOrder, []packItem, error) {
+	var order binary.ByteOrder = binary.LittleEndian
+	if len(format) > 0 {
+		switch format[0] {
+		case '<':
+			format = format[1:]
+		case '>', '!':
+			order = binary.BigEndian
+			format = format[1:]
+		}
+	}
+
+	var items []packItem
+	for i := 0; i < len(format); i++ {
+		count := -1
+		for ; i < len(format) && '0' <= format[i] && format[i] <= '9'; i++ {
+			if count < 0 {
+				count = 0
+			}
+			count = count*10 + int(format[i]-'0')
+		}
+		if i == len(format) {
+			return nil, nil, fmt.Errorf("Pack format %q ends with a count", format)
+		}
+
+		code := format[i]
+		if _, ok := packSizes[code]; !ok && code != 's' {
+			if code == ' ' {
+				continue
+			}
+			return nil, nil, fmt.Errorf("Unknown pack code '%c' in format %q", code, format)
+		}
+		if count < 0 {
+			count = 1
+		}
+		items = append(items, packItem{code: code, count: count})
+	}
+	return order, items, nil
+}
+
+func packValue(buf *bytes.Buffer, order binary.ByteOrder, item packItem, value Object) error {
+	var data interface{}
+	switch item.code {
+	case 's':
+		b, ok := bytesValue(value)
+		if !ok {
+			return fmt.Errorf("expect String or Bytes")
+		}
+		padded := make([]byte, item.count)
+		copy(padded, b)
+		buf.Write(padded)
+		return nil
+	case '?':
+		data = value.IsTruthy()
+	case 'f', 'd':
+		n, ok := value.(Numeric)
+		if !ok {
+			return fmt.Errorf("expect Numeric")
+		}
+		if item.code == 'f' {
+			data = float32(n.floatValue())
+		} else {
+			data = n.floatValue()
+		}
+	case 'q', 'Q':
+		// 64 bit values may be larger than an Integer holds
+		if bi, ok := value.(*BigIntegerObject); ok {
+			if item.code == 'q' && bi.value.IsInt64() {
+				data = bi.value.Int64()
+			} else if item.code == 'Q' && bi.value.IsUint64() {
+				data = bi.value.Uint64()
+			} else {
+				return fmt.Errorf("out of range")
+			}
+			break
+		}
+		n, ok := value.(IntegerObject)
+		if !ok {
+			return fmt.Errorf("expect Integer")
+		}
+		if item.code == 'q' {
+			data = int64(n)
+		} else {
+			data = uint64(n)
+		}
+	default:
+		n, ok := value.(IntegerObject)
+		if !ok {
+			return fmt.Errorf("expect Integer")
+		}
+		switch item.code {
+		case 'b':
+			data = int8(n)
+		case 'B':
+			data = uint8(n)
+		case 'h':
+			data = int16(n)
+		case 'H':
+			data = uint16(n)
+		case 'i':
+			data = int32(n)
+		case 'I':
+			data = uint32(n)
+		}
+	}
+	return binary.Write(buf, order, data)
+}
+
+func unpackValue(order binary.ByteOrder, code byte, b []byte) Object {
+	switch code {
+	case '?':
+		return BooleanObject(b[0] != 0)
+	case 'b':
+		return IntegerObject(int8(b[0]))
+	case 'B':
+		return IntegerObject(b[0])
+	case 'h':
+		return IntegerObject(int16(order.Uint16(b)))
+	case 'H':
+		return IntegerObject(order.Uint16(b))
+	case 'i':
+		return IntegerObject(int32(order.Uint32(b)))
+	case 'I':
+		return IntegerObject(order.Uint32(b))
+	case 'q':
+		return IntegerObject(int64(order.Uint64(b)))
+	case 'Q':
+		return integerResult(new(big.Int).SetUint64(order.Uint64(b)))
+	case 'f':
+		return FloatObject(math.Float32frombits(order.Uint32(b)))
+	case 'd':
+		return FloatObject(math.Float64frombits(order.Uint64(b)))
+	}
+	return NIL
+}
+
+// Value returns the bytes
+func (b *BytesObject) Value() interface{} {
+	return b.bytes
+}
+
+// ToString returns the bytes as a string
+func (b *BytesObject) ToString(t *Thread) string {
+	return string(b.bytes)
+}
+
+// Inspect returns the bytes in hex
+func (b *BytesObject) Inspect(t *Thread) string {
+	return "#<Bytes " + hex.EncodeToString(b.bytes) + ">"
+}
+
+// ToJSON returns the bytes as a base64 encoded JSON string
+func (b *BytesObject) ToJSON(t *Thread) string {
+	return strconv.Quote(base64.StdEncoding.EncodeToString(b.bytes))
+}
+
+// EqualTo returns true if the other object is a Bytes holding the same bytes
+func (b *BytesObject) EqualTo(with Object) bool {
+	right, ok := with.(*BytesObject)
+	return ok && bytes.Equal(b.bytes, right.bytes)
+}
@@ -54,6 +54,7 @@ type goCallFrame struct {
 	argPtr   int
 	argCount int
 	name     string
+	argSet   *bytecode.ArgSet // names of the arguments passed by keyword
 }
 
 func (cf *goCallFrame) stopExecution() {}
@@ -61,11 +62,13 @@ func (cf *goCallFrame) stopExecution() {}
 // CallFrame structure to hold a callframe
 type CallFrame struct {
 	baseFrame
-	sync.Mutex
-	locals         []*Pointer               // local variables
+	lock           *sync.Mutex              // only set when blocks use the locals, as they may run on other threads
+	locals         []Object                 // local variables, which are nil until set
 	ep             *CallFrame               // environment pointer, points to the call frame we want to get locals from
 	instructionSet *bytecode.InstructionSet // bytecode to execute
 	pc             int                      // program counter
+	native         func([]Object) Object    // run instead of the bytecode, for blocks passed by builtin methods
+	bound          bool                     // set when a binding refers to the frame, so it isn't reused
 }
 
 func (cf *CallFrame) instructionsCount() int {
@@ -78,7 +81,7 @@ func (cf *CallFrame) stopExecution() {
 
 // IsEmpty returns true if there are no instructions in the callframe
 func (cf *CallFrame) IsEmpty() bool {
-	return len(cf.instructionSet.Instructions) == 0 || cf.instructionSet.Instructions[0] == bytecode.Leave
+	return cf.native == nil && (len(cf.instructionSet.Instructions) == 0 || cf.instructionSet.Instructions[0] == bytecode.Leave)
 }
 
 func (cf *baseFrame) Self() Object {
@@ -112,76 +115,72 @@ func (cf *baseFrame) FileName() string {
 	return cf.fileName
 }
 
-// TODO: see if we can remove the locking here
-func (cf *CallFrame) getLocal(index, depth int) (p *Pointer) {
+func (cf *CallFrame) getLocal(index, depth int) (o Object) {
 	lcf := cf
 	for depth > 0 {
 		lcf = lcf.ep
 		depth--
 	}
 
-	lcf.Lock()
-	defer lcf.Unlock()
+	if lcf.lock != nil {
+		lcf.lock.Lock()
+		defer lcf.lock.Unlock()
+	}
 
 	if index < len(lcf.locals) {
-		p = lcf.locals[index]
+		o = lcf.locals[index]
 	}
 	return
 }
 
-func (cf *CallFrame) getLocalFast(index int) (p *Pointer) {
-	if index < len(cf.locals) {
-		p = cf.locals[index]
+// localValue returns the value of the local, which is nil if it hasn't been set
+func (cf *CallFrame) localValue(index, depth int) Object {
+	if depth == 0 && cf.lock == nil {
+		if index < len(cf.locals) && cf.locals[index] != nil {
+			return cf.locals[index]
+		}
+		return NIL
 	}
-	return
+	o := cf.getLocal(index, depth)
+	if o == nil {
+		return NIL
+	}
+	return o
 }
 
 func (cf *CallFrame) insertLocal(index, depth int, value Object) {
-	existingLocal := cf.getLocal(index, depth)
-	if existingLocal != nil {
-		existingLocal.Target = value
-		return
+	lcf := cf
+	for depth > 0 {
+		lcf = lcf.ep
+		depth--
 	}
 
-	cf.Lock()
-	defer cf.Unlock()
-
-	if cf.locals == nil {
-		cf.locals = make([]*Pointer, index+1)
-	} else {
-		for index >= len(cf.locals) {
-			cf.locals = append(cf.locals, nil)
-		}
+	if lcf.lock != nil {
+		lcf.lock.Lock()
+		defer lcf.lock.Unlock()
 	}
 
-	cf.locals[index] = &Pointer{Target: value}
+	lcf.insertLocalFast(index, value)
 }
 
 func (cf *CallFrame) initLocals(size int) {
-	cf.locals = make([]*Pointer, size)
+	if cap(cf.locals) >= size {
+		cf.locals = cf.locals[:size]
+		return
+	}
+	cf.locals = make([]Object, size)
 }
 
 func (cf *CallFrame) initLocalsFrom(objs ...Object) {
-	cf.locals = make([]*Pointer, len(objs))
-	for i, v := range objs {
-		cf.locals[i] = &Pointer{Target: v}
-	}
+	cf.initLocals(len(objs))
+	copy(cf.locals, objs)
 }
 
 func (cf *CallFrame) insertLocalFast(index int, o Object) {
-	if cf.locals == nil {
-		cf.locals = make([]*Pointer, index+1)
-	} else {
-		for index >= len(cf.locals) {
-			cf.locals = append(cf.locals, nil)
-		}
-	}
-
-	if cf.locals[index] != nil {
-		cf.locals[index].Target = o
-	} else {
-		cf.locals[index] = &Pointer{Target: o}
+	for index >= len(cf.locals) {
+		cf.locals = append(cf.locals, nil)
 	}
+	cf.locals[index] = o
 }
 
 func (cf *baseFrame) storeConstant(constName string, constant Object) (ptr *Pointer) {
@@ -287,7 +286,53 @@ func (cfs *callFrameStack) top() callFrame {
 }
 
 func newNormalCallFrame(is *bytecode.InstructionSet, filename string, sourceLine int) *CallFrame {
-	return &CallFrame{baseFrame: baseFrame{fileName: filename, sourceLine: sourceLine}, instructionSet: is}
+	cf := &CallFrame{baseFrame: baseFrame{fileName: filename, sourceLine: sourceLine}, instructionSet: is}
+	if is.LocalsCaptured {
+		cf.lock = &sync.Mutex{}
+	}
+	return cf
+}
+
+// maxPooledFrames is the number of released frames a thread keeps for reuse
+const maxPooledFrames = 256
+
+// newFrame returns a frame for the instructions, reusing a released one if there is one
+func (t *Thread) newFrame(is *bytecode.InstructionSet, filename string, sourceLine int) *CallFrame {
+	n := len(t.framePool)
+	if n == 0 || is.LocalsCaptured {
+		return newNormalCallFrame(is, filename, sourceLine)
+	}
+	cf := t.framePool[n-1]
+	t.framePool = t.framePool[:n-1]
+	cf.instructionSet = is
+	cf.fileName = filename
+	cf.sourceLine = sourceLine
+	return cf
+}
+
+// releaseFrame returns a frame which has finished to the pool.
+// Frames which created blocks or bindings are not reused, as they may still refer to them.
+func (t *Thread) releaseFrame(cf *CallFrame) {
+	if cf.instructionSet.HasBlocks || cf.lock != nil || cf.native != nil || cf.bound || len(t.framePool) >= maxPooledFrames {
+		return
+	}
+	locals := cf.locals
+	for i := range locals {
+		locals[i] = nil
+	}
+	*cf = CallFrame{locals: locals[:0]}
+	t.framePool = append(t.framePool, cf)
+}
+
+// nativeInstructions is the empty instruction set of native block frames
+var nativeInstructions = &bytecode.InstructionSet{Name: "<native>", Filename: "<native>", SourceMap: []int{0}}
+
+// newNativeBlockFrame returns a block frame which calls fn with the arguments it is yielded
+func newNativeBlockFrame(fn func([]Object) Object) *CallFrame {
+	cf := newNormalCallFrame(nativeInstructions, nativeInstructions.Filename, 0)
+	cf.native = fn
+	cf.isBlock = true
+	return cf
 }
 
 func newGoCallFrame(m Method, receiver Object, argCount, argPtr int, n, filename string, sourceLine int, blockFrame *CallFrame) *goCallFrame {
@@ -30,6 +30,8 @@ type RClass struct {
 	constants      map[string]*Pointer
 	scope          *RClass
 	inheritsLookup bool
+	// members are the fields of a class made by Struct new
+	members []string
 }
 
 // ClassLoader can be registered with a vm so that it can load this library at vm creation
@@ -193,6 +195,152 @@ var moduleCommonClassMethods = []*BuiltinMethodObject{
 			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "a module", args[0].Class().Name)
 		},
 	},
+	{
+		Name: "define_method",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			class, ok := receiver.(*RClass)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.ClassClass, receiver.Class().Name)
+			}
+			if len(args) < 1 || len(args) > 2 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentRange, 1, 2, len(args))
+			}
+			name, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+			}
+
+			var block *BlockObject
+			if len(args) == 2 {
+				if block, ok = args[1].(*BlockObject); !ok {
+					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.BlockClass, args[1].Class().Name)
+				}
+			} else if blockFrame := t.GetBlock(); blockFrame != nil && !blockFrame.IsEmpty() {
+				block = blockFrame.blockObject(t.vm, class)
+			} else {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, "Can't define method %s without a block", string(name))
+			}
+			if block.native != nil {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, "Can't define method %s from a native block", string(name))
+			}
+
+			method := t.vm.blockMethod(string(name), block)
+			class.setMethod(string(name), method)
+			t.callHook(class, "method_added", name)
+			return method
+		},
+	},
+	{
+		Name: "remove_method",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			class, ok := receiver.(*RClass)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.ClassClass, receiver.Class().Name)
+			}
+			for _, arg := range args {
+				name, ok := arg.(StringObject)
+				if !ok {
+					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, arg.Class().Name)
+				}
+				if class.Methods[string(name)] == nil {
+					return t.vm.InitErrorObject(t, errors.NameError, "Method '%s' not defined in %s", string(name), class.Name)
+				}
+				class.removeMethod(string(name))
+			}
+			return class
+		},
+	},
+	{
+		Name: "undef_method",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			class, ok := receiver.(*RClass)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.ClassClass, receiver.Class().Name)
+			}
+			for _, arg := range args {
+				name, ok := arg.(StringObject)
+				if !ok {
+					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, arg.Class().Name)
+				}
+				if class.lookupMethod(string(name)) == nil {
+					return t.vm.InitErrorObject(t, errors.NameError, errors.UndefinedMethod, string(name), class.Name)
+				}
+				// A nil method stops the lookup going on to the superclasses
+				class.setMethod(string(name), nil)
+			}
+			return class
+		},
+	},
+	{
+		Name: "alias_method",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			class, ok := receiver.(*RClass)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.ClassClass, receiver.Class().Name)
+			}
+			if len(args) != 2 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 2, len(args))
+			}
+			newName, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 1, classes.StringClass, args[0].Class().Name)
+			}
+			oldName, ok := args[1].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 2, classes.StringClass, args[1].Class().Name)
+			}
+
+			method := class.lookupMethod(string(oldName))
+			if method == nil {
+				return t.vm.InitErrorObject(t, errors.NameError, errors.UndefinedMethod, string(oldName), class.Name)
+			}
+			class.setMethod(string(newName), method)
+			t.callHook(class, "method_added", newName)
+			return class
+		},
+	},
+	{
+		Name: "inherited",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return NIL
+		},
+	},
+	{
+		Name: "included",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return NIL
+		},
+	},
+	{
+		Name: "extended",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return NIL
+		},
+	},
+	{
+		Name: "method_added",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return NIL
+		},
+	},
+	{
+		Name: "private",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return setVisibility(receiver, t, args, Private)
+		},
+	},
+	{
+		Name: "protected",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return setVisibility(receiver, t, args, Protected)
+		},
+	},
+	{
+		Name: "public",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return setVisibility(receiver, t, args, Public)
+		},
+	},
 	{
 		Name: "property",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
@@ -264,6 +412,8 @@ var moduleCommonClassMethods = []*BuiltinMethodObject{
 			module = &rClass
 			module.superClass = class.superClass
 			class.superClass = module
+			invalidateMethodCaches()
+			t.callHook(args[0], "extended", receiver)
 
 			return class
 		},
@@ -287,16 +437,8 @@ var moduleCommonClassMethods = []*BuiltinMethodObject{
 				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "a class", r.Class().Name)
 			}
 
-			if class.alreadyInherit(module) {
-				return class
-			}
-
-			// Make a copy of the module
-			rClass := *module
-			module = &rClass
-			module.superClass = class.superClass
-			class.superClass = module
-
+			class.include(module)
+			t.callHook(module, "included", class)
 			return class
 		},
 	},
@@ -458,6 +600,7 @@ var classCommonInstanceMethods = []*BuiltinMethodObject{
 			case *RObject:
 				newObj := receiver.Class().initInstance()
 				newObj.SetVariables(receiver.Variables().copy())
+				t.vm.dupSingletonClass(receiver, newObj)
 				return newObj
 			default:
 				return receiver
@@ -493,6 +636,31 @@ var classCommonInstanceMethods = []*BuiltinMethodObject{
 		},
 		Primitive: true,
 	},
+	{
+		// Returns a Binding of the caller's locals
+		Name: "binding",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return newBindingObject(t.callerFrame())
+		},
+		Primitive: true,
+	},
+	{
+		// Runs the code with the caller's locals, and returns the value of the last statement
+		Name: "eval",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			code, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+			}
+			return newBindingObject(t.callerFrame()).eval(t, string(code))
+		},
+	},
 	{
 		// TODO: Can we combine with "tap"?
 		Name: "instance_eval",
@@ -557,13 +725,12 @@ var classCommonInstanceMethods = []*BuiltinMethodObject{
 	{
 		Name: "methods",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
-			var klasses []*RClass
-			if class, ok := receiver.(*RClass); ok {
-				if class.MetaClass() != nil {
-					klasses = append(klasses, class.MetaClass())
-				}
+			klasses := ownClasses(receiver)
+			filter, err := visibilityFilter(t, args)
+			if err != nil {
+				return err
 			}
-			methods := getMethods(append(klasses, receiver.Class().ancestors()...))
+			methods := getMethods(append(klasses, receiver.Class().ancestors()...), filter)
 			return InitArrayObject(methods)
 		},
 		Primitive: true,
@@ -571,13 +738,11 @@ var classCommonInstanceMethods = []*BuiltinMethodObject{
 	{
 		Name: "own_methods",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
-			klasses := []*RClass{receiver.Class()}
-			if class, ok := receiver.(*RClass); ok {
-				if class.MetaClass() != nil {
-					klasses = []*RClass{class.MetaClass(), receiver.Class()}
-				}
+			filter, err := visibilityFilter(t, args)
+			if err != nil {
+				return err
 			}
-			methods := getMethods(klasses)
+			methods := getMethods(append(ownClasses(receiver), receiver.Class()), filter)
 			return InitArrayObject(methods)
 		},
 		Primitive: true,
@@ -791,15 +956,24 @@ var classCommonInstanceMethods = []*BuiltinMethodObject{
 	{
 		Name: "metaclass",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
-			if class, ok := receiver.(*RClass); ok {
-				if class.MetaClass() == nil {
-					metaClass := createRClass(t.vm, fmt.Sprintf("#<Class:#<%s:metaclass>>", receiver.Class().Name))
-					class.SetMetaClass(metaClass)
-					return metaClass
-				}
-				return class.MetaClass()
+			if _, ok := receiver.(*RClass); !ok {
+				return NIL
 			}
-			return NIL
+			class, _ := t.singletonClassOf(receiver)
+			return class
+		},
+	},
+	{
+		Name: "singleton_class",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			class, err := t.singletonClassOf(receiver)
+			if err != nil {
+				return err
+			}
+			return class
 		},
 	},
 	{
@@ -835,6 +1009,7 @@ var classCommonInstanceMethods = []*BuiltinMethodObject{
 				cf.ep = blockFrame.ep
 				cf.self = receiver
 				cf.isBlock = true
+				cf.native = blockFrame.native
 				t.Yield(cf, receiver)
 			}
 
@@ -870,6 +1045,12 @@ var classCommonInstanceMethods = []*BuiltinMethodObject{
 			return StringObject(receiver.Inspect(t))
 		},
 	},
+	{
+		Name: "json",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return StringObject(receiver.ToJSON(t))
+		},
+	},
 	{
 		Name: "<-",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
@@ -1068,6 +1249,7 @@ func (c *RClass) inherits(sc *RClass) *RClass {
 	c.pseudoSuperClass = sc
 	c.metaClass.superClass = sc.metaClass
 	c.metaClass.pseudoSuperClass = sc.metaClass
+	invalidateMethodCaches()
 	return c
 }
 
@@ -1076,6 +1258,7 @@ func (c *RClass) InstanceMethods(methodList []*BuiltinMethodObject) *RClass {
 	for _, m := range methodList {
 		c.Methods[m.Name] = m
 	}
+	invalidateMethodCaches()
 	return c
 }
 
@@ -1085,6 +1268,7 @@ func (c *RClass) ClassMethods(methodList []*BuiltinMethodObject) *RClass {
 		c.metaClass.Methods[m.Name] = m
 		c.Methods[m.Name] = m
 	}
+	invalidateMethodCaches()
 	return c
 }
 
@@ -1157,6 +1341,22 @@ func (c *RClass) getClassConstant(constName string) (class *RClass) {
 	panic(constName + " is not a class.")
 }
 
+// include inserts a copy of the module above the class in its method lookup
+func (c *RClass) include(module *RClass) *RClass {
+	if c.alreadyInherit(module) {
+		return c
+	}
+
+	// Make a copy of the module
+	rClass := *module
+	module = &rClass
+	module.superClass = c.superClass
+	c.superClass = module
+	invalidateMethodCaches()
+
+	return c
+}
+
 func (c *RClass) alreadyInherit(constant *RClass) bool {
 	if c.superClass == constant {
 		return true
@@ -1188,7 +1388,7 @@ func (c *RClass) addGetter(args []Object) {
 func (c *RClass) generateMethod(args []Object, suffix string, generate func(string) *BuiltinMethodObject) {
 	for _, attr := range args {
 		if attrName, ok := attr.(StringObject); ok {
-			c.Methods[string(attrName)+suffix] = generate(string(attrName))
+			c.setMethod(string(attrName)+suffix, generate(string(attrName)))
 		}
 	}
 }
@@ -1272,13 +1472,41 @@ func classIsA(receiver Object, t *Thread, args []Object) Object {
 	return BooleanObject(receiver.Class().isA(rClass))
 }
 
-func getMethods(klasses []*RClass) (methods []Object) {
+// ownClasses returns the metaclass of a class, or the singleton class of another object,
+// if it has one
+func ownClasses(receiver Object) []*RClass {
+	switch r := receiver.(type) {
+	case *RClass:
+		if r.MetaClass() != nil {
+			return []*RClass{r.MetaClass()}
+		}
+	case singletonObject:
+		if r.singletonClass() != nil {
+			return []*RClass{r.singletonClass()}
+		}
+	}
+	return nil
+}
+
+// callHook calls the hook method on the receiver, if it has been defined in Lito
+func (t *Thread) callHook(receiver Object, name string, args ...Object) {
+	if _, ok := receiver.FindMethod(name, false).(*MethodObject); ok {
+		t.CallMethod(receiver, name, args...)
+	}
+}
+
+// getMethods returns the names of the methods of the classes whose visibility the filter accepts.
+// A method's visibility is taken from the first class defining it.
+func getMethods(klasses []*RClass, filter func(Visibility) bool) (methods []Object) {
 	set := map[string]bool{}
 	for _, klass := range klasses {
 		for _, name := range klass.Methods.names() {
 			if !set[name] {
 				set[name] = true
-				methods = append(methods, StringObject(name))
+				method := klass.Methods[name]
+				if v, _ := methodVisibility(method); method != nil && filter(v) {
+					methods = append(methods, StringObject(name))
+				}
 			}
 		}
 	}
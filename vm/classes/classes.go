@@ -1,24 +1,38 @@
 package classes
 
 const (
-	ObjectClass    = "Object"
-	ErrorClass     = "Error"
-	ClassClass     = "Class"
-	ModuleClass    = "Module"
-	IntegerClass   = "Integer"
-	FloatClass     = "Float"
-	StringClass    = "String"
-	ArrayClass     = "Array"
-	HashClass      = "Hash" // TODO: Rename to Map
-	BooleanClass   = "Boolean"
-	NilClass       = "Nil"
-	ChannelClass   = "Channel"
-	RangeClass     = "Range"
-	MethodClass    = "Method"
-	GoObjectClass  = "GoObject"
-	FileClass      = "File"
-	RegexpClass    = "Regexp"
-	BlockClass     = "Block"
-	WaitGroupClass = "WaitGroup"
-	SystemClass    = "System"
+	ObjectClass        = "Object"
+	ErrorClass         = "Error"
+	ClassClass         = "Class"
+	ModuleClass        = "Module"
+	IntegerClass       = "Integer"
+	FloatClass         = "Float"
+	StringClass        = "String"
+	StringBuilderClass = "StringBuilder"
+	ArrayClass         = "Array"
+	HashClass          = "Hash" // TODO: Rename to Map
+	BooleanClass       = "Boolean"
+	NilClass           = "Nil"
+	ChannelClass       = "Channel"
+	RangeClass         = "Range"
+	MethodClass        = "Method"
+	GoObjectClass      = "GoObject"
+	FileClass          = "File"
+	RegexpClass        = "Regexp"
+	MatchDataClass     = "MatchData"
+	BlockClass         = "Block"
+	BytesClass         = "Bytes"
+	MathClass          = "Math"
+	BigIntegerClass    = "BigInteger"
+	DecimalClass       = "Decimal"
+	RationalClass      = "Rational"
+	EnumerableModule   = "Enumerable"
+	GeneratorClass     = "Generator"
+	YielderClass       = "Yielder"
+	LazyClass          = "Lazy"
+	SetClass           = "Set"
+	StructClass        = "Struct"
+	WaitGroupClass     = "WaitGroup"
+	SystemClass        = "System"
+	BindingClass       = "Binding"
 )
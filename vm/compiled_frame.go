@@ -0,0 +1,423 @@
+package vm
+
+import (
+	"github.com/robotii/lito/compiler/bytecode"
+)
+
+// opFunc runs a compiled instruction on the frame
+type opFunc func(t *Thread, cf *CallFrame)
+
+// compiledOp is an instruction compiled to a closure, with its operands,
+// constants and jump targets resolved
+type compiledOp struct {
+	fn   opFunc
+	line int
+	// next is the offset of the following instruction
+	next int
+}
+
+// compiledInstructions holds the compiled ops at the offsets of their instructions,
+// so that jump targets and the program counter keep their meaning
+type compiledInstructions []compiledOp
+
+// compiledOps returns the instruction set compiled to closures, compiling it the first time
+func compiledOps(is *bytecode.InstructionSet) compiledInstructions {
+	if ops, ok := is.Compiled.Load().(compiledInstructions); ok {
+		return ops
+	}
+	ops := compileInstructions(is)
+	is.Compiled.Store(ops)
+	return ops
+}
+
+// execCompiledFrame runs the frame with the closure engine
+func (t *Thread) execCompiledFrame(cf *CallFrame) {
+	// Deferred blocks are kept on the thread, and the ones added by this frame
+	// are run when it finishes
+	base := len(t.deferStack)
+	defer func() {
+		if len(t.deferStack) > base {
+			deferStack := append([]*CallFrame(nil), t.deferStack[base:]...)
+			t.deferStack = t.deferStack[:base]
+			t.runDefers(deferStack)
+		}
+	}()
+
+	is := cf.instructionSet
+	ops := compiledOps(is)
+	for cf.pc < len(ops) {
+		op := &ops[cf.pc]
+		t.currentLine = op.line
+		cf.pc = op.next
+		op.fn(t, cf)
+		// Tail calls carry on in the same frame with the method called
+		if cf.instructionSet != is {
+			is = cf.instructionSet
+			ops = compiledOps(is)
+		}
+	}
+}
+
+func compileInstructions(is *bytecode.InstructionSet) compiledInstructions {
+	code := is.Instructions
+	ops := make(compiledInstructions, len(code))
+	for pc := 0; pc < len(code); {
+		opcode := code[pc]
+		next := pc + 1 + bytecode.ParamCount(opcode)
+		ops[pc] = compiledOp{fn: compileInstruction(is, opcode, pc+1), line: is.SourceMap[pc], next: next}
+		pc = next
+	}
+	return ops
+}
+
+// compileInstruction returns the closure for the instruction whose operands start at pc
+func compileInstruction(is *bytecode.InstructionSet, opcode int, pc int) opFunc {
+	code := is.Instructions
+
+	switch opcode {
+	case bytecode.NoOp:
+		return func(t *Thread, cf *CallFrame) {}
+
+	case bytecode.Add, bytecode.Subtract,
+		bytecode.Greater, bytecode.GreaterEqual,
+		bytecode.Less, bytecode.LessEqual:
+		methodName := is.GetString(pc)
+		site, _ := is.GetObject(pc + 1).(*bytecode.CallSite)
+		return compileIntegerOp(opcode, methodName, site)
+
+	case bytecode.Pop:
+		return func(t *Thread, cf *CallFrame) {
+			t.Stack.Discard()
+		}
+
+	case bytecode.Dup:
+		return func(t *Thread, cf *CallFrame) {
+			t.Stack.Push(t.Stack.top())
+		}
+
+	case bytecode.PutTrue:
+		return pushConstant(TRUE)
+
+	case bytecode.PutFalse:
+		return pushConstant(FALSE)
+
+	case bytecode.PutNull:
+		return pushConstant(NIL)
+
+	case bytecode.PutInt:
+		return pushConstant(IntegerObject(code[pc]))
+
+	case bytecode.PutString:
+		return pushConstant(StringObject(is.GetString(pc)))
+
+	case bytecode.PutFloat:
+		return pushConstant(FloatObject(is.GetFloat(pc)))
+
+	case bytecode.PutObject:
+		obj := is.GetObject(pc)
+		return func(t *Thread, cf *CallFrame) {
+			t.Stack.Push(t.vm.InitObjectFromGoType(obj))
+		}
+
+	case bytecode.PutRegexp:
+		pattern := is.GetString(pc)
+		return func(t *Thread, cf *CallFrame) {
+			t.putRegexp(pattern)
+		}
+
+	case bytecode.PutSelf:
+		return func(t *Thread, cf *CallFrame) {
+			t.Stack.Push(cf.self)
+		}
+
+	case bytecode.PutSuper:
+		return func(t *Thread, cf *CallFrame) {
+			t.Stack.Push(cf.self)
+			t.Stack.PushFlags(superRef)
+		}
+
+	case bytecode.GetConstant, bytecode.GetConstantNamespace:
+		constName := is.GetString(pc)
+		isNamespace := opcode == bytecode.GetConstantNamespace
+		return func(t *Thread, cf *CallFrame) {
+			t.getConstant(cf, constName, isNamespace)
+		}
+
+	case bytecode.SetConstant:
+		constName := is.GetString(pc)
+		return func(t *Thread, cf *CallFrame) {
+			t.setConstant(cf, constName)
+		}
+
+	case bytecode.GetLocal:
+		depth, index := code[pc], code[pc+1]
+		return func(t *Thread, cf *CallFrame) {
+			t.Stack.Push(cf.localValue(index, depth))
+		}
+
+	case bytecode.SetLocal:
+		depth, index := code[pc], code[pc+1]
+		return func(t *Thread, cf *CallFrame) {
+			cf.insertLocal(index, depth, t.Stack.top())
+		}
+
+	case bytecode.SetOptional:
+		depth, index := code[pc], code[pc+1]
+		return func(t *Thread, cf *CallFrame) {
+			p := t.Stack.Pop()
+			if cf.getLocal(index, depth) == nil {
+				cf.insertLocal(index, depth, p)
+			}
+		}
+
+	case bytecode.GetLocalAddInt:
+		depth, index, n := code[pc], code[pc+1], code[pc+2]
+		methodName := is.GetString(pc + 3)
+		site, _ := is.GetObject(pc + 4).(*bytecode.CallSite)
+		operand := Object(IntegerObject(n))
+		return func(t *Thread, cf *CallFrame) {
+			obj := cf.localValue(index, depth)
+			if i, ok := obj.(IntegerObject); ok {
+				if sum, ok := addInt(int(i), n); ok {
+					t.Stack.Push(IntegerObject(sum))
+					return
+				}
+			}
+			t.Stack.Push(obj)
+			t.Stack.Push(operand)
+			t.binaryOperator(cf, methodName, site)
+		}
+
+	case bytecode.GetLocalSend:
+		depth, index := code[pc], code[pc+1]
+		send := compileSend(is, pc+2)
+		return func(t *Thread, cf *CallFrame) {
+			t.Stack.Push(cf.localValue(index, depth))
+			send(t, cf)
+		}
+
+	case bytecode.GetInstanceVariable:
+		variableName := is.GetString(pc)
+		return func(t *Thread, cf *CallFrame) {
+			v, ok := cf.self.GetVariable(variableName)
+			if !ok {
+				v = NIL
+			}
+			t.Stack.Push(v)
+		}
+
+	case bytecode.SetInstanceVariable:
+		variableName := is.GetString(pc)
+		return func(t *Thread, cf *CallFrame) {
+			cf.self.SetVariable(variableName, t.Stack.top())
+		}
+
+	case bytecode.NewRange, bytecode.NewRangeExcl:
+		exclusive := opcode == bytecode.NewRangeExcl
+		return func(t *Thread, cf *CallFrame) {
+			t.newRange(exclusive)
+		}
+
+	case bytecode.NewArray:
+		argCount := code[pc]
+		return func(t *Thread, cf *CallFrame) {
+			t.newArray(argCount)
+		}
+
+	case bytecode.ExpandArray:
+		arrLength := code[pc]
+		return func(t *Thread, cf *CallFrame) {
+			t.expandArray(arrLength)
+		}
+
+	case bytecode.NewHash:
+		argCount := code[pc]
+		return func(t *Thread, cf *CallFrame) {
+			t.newHash(argCount)
+		}
+
+	case bytecode.SplatArray:
+		return func(t *Thread, cf *CallFrame) {
+			if arr, ok := t.Stack.top().(*ArrayObject); ok {
+				arr.splat = true
+			}
+		}
+
+	case bytecode.SplatBlock:
+		return func(t *Thread, cf *CallFrame) {
+			if blk, ok := t.Stack.top().(*BlockObject); ok {
+				blk.splat = true
+			}
+		}
+
+	case bytecode.BranchUnless:
+		target := code[pc]
+		return func(t *Thread, cf *CallFrame) {
+			if isFalsy(t.Stack.Pop()) {
+				cf.pc = target
+			}
+		}
+
+	case bytecode.BranchIf:
+		target := code[pc]
+		return func(t *Thread, cf *CallFrame) {
+			if !isFalsy(t.Stack.Pop()) {
+				cf.pc = target
+			}
+		}
+
+	case bytecode.Jump:
+		target := code[pc]
+		return func(t *Thread, cf *CallFrame) {
+			cf.pc = target
+		}
+
+	case bytecode.Break:
+		return func(t *Thread, cf *CallFrame) {
+			t.breakBlock(cf)
+		}
+
+	case bytecode.Leave:
+		return func(t *Thread, cf *CallFrame) {
+			cf.stopExecution()
+		}
+
+	case bytecode.DefMethod, bytecode.DefMetaMethod:
+		argCount := code[pc]
+		methodName := is.GetString(pc + 1)
+		methodIS, _ := is.GetObject(pc + 2).(*bytecode.InstructionSet)
+		meta := opcode == bytecode.DefMetaMethod
+		return func(t *Thread, cf *CallFrame) {
+			t.defMethod(methodName, argCount, methodIS, meta)
+		}
+
+	case bytecode.DefClass:
+		subjectType, subjectName := is.GetString(pc), is.GetString(pc+1)
+		classIS := is.GetObject(pc + 2).(*bytecode.InstructionSet)
+		superClassName := is.GetString(pc + 3)
+		return func(t *Thread, cf *CallFrame) {
+			t.defClass(cf, subjectType, subjectName, classIS, superClassName)
+		}
+
+	case bytecode.Send:
+		return compileSend(is, pc)
+
+	case bytecode.TailSend:
+		methodName := is.GetString(pc)
+		argCount := code[pc+1]
+		argSet, _ := is.GetObject(pc + 3).(*bytecode.ArgSet)
+		site, _ := is.GetObject(pc + 4).(*bytecode.CallSite)
+		send := compileSend(is, pc)
+		return func(t *Thread, cf *CallFrame) {
+			if !t.tailCall(cf, methodName, argCount, argSet, site) {
+				send(t, cf)
+			}
+		}
+
+	case bytecode.BinaryOperator:
+		methodName := is.GetString(pc)
+		site, _ := is.GetObject(pc + 1).(*bytecode.CallSite)
+		return func(t *Thread, cf *CallFrame) {
+			t.binaryOperator(cf, methodName, site)
+		}
+
+	case bytecode.InvokeBlock:
+		argCount := code[pc]
+		return func(t *Thread, cf *CallFrame) {
+			t.invokeBlock(cf, argCount)
+		}
+
+	case bytecode.GetBlock:
+		return func(t *Thread, cf *CallFrame) {
+			t.getBlock(cf)
+		}
+
+	case bytecode.HasBlock:
+		return func(t *Thread, cf *CallFrame) {
+			t.Stack.Push(BooleanObject(cf.blockFrame != nil))
+		}
+
+	case bytecode.Defer:
+		argCount := code[pc]
+		blockIS, _ := is.GetObject(pc + 1).(*bytecode.InstructionSet)
+		return func(t *Thread, cf *CallFrame) {
+			if c := t.deferBlock(cf, argCount, blockIS); c != nil {
+				t.deferStack = append(t.deferStack, c)
+			}
+		}
+	}
+
+	return func(t *Thread, cf *CallFrame) {
+		panic("Unexpected bytecode")
+	}
+}
+
+func pushConstant(o Object) opFunc {
+	return func(t *Thread, cf *CallFrame) {
+		t.Stack.Push(o)
+	}
+}
+
+// compileSend returns the closure for a send whose operands start at pc
+func compileSend(is *bytecode.InstructionSet, pc int) opFunc {
+	methodName := is.GetString(pc)
+	argCount := is.Instructions[pc+1]
+	blockIS, _ := is.GetObject(pc + 2).(*bytecode.InstructionSet)
+	// We don't want a panic here, nil is fine for argset
+	argSet, _ := is.GetObject(pc + 3).(*bytecode.ArgSet)
+	site, _ := is.GetObject(pc + 4).(*bytecode.CallSite)
+	return func(t *Thread, cf *CallFrame) {
+		t.send(cf, methodName, argCount, blockIS, argSet, site)
+	}
+}
+
+// compileIntegerOp returns the closure for an operator with a fast path for Integers,
+// which calls the method when either operand isn't an Integer or the result overflows
+func compileIntegerOp(opcode int, methodName string, site *bytecode.CallSite) opFunc {
+	var op func(l, r int) (Object, bool)
+	switch opcode {
+	case bytecode.Add:
+		op = func(l, r int) (Object, bool) {
+			sum, ok := addInt(l, r)
+			return IntegerObject(sum), ok
+		}
+	case bytecode.Subtract:
+		op = func(l, r int) (Object, bool) {
+			diff, ok := subInt(l, r)
+			return IntegerObject(diff), ok
+		}
+	case bytecode.Less:
+		op = func(l, r int) (Object, bool) { return BooleanObject(l < r), true }
+	case bytecode.Greater:
+		op = func(l, r int) (Object, bool) { return BooleanObject(l > r), true }
+	case bytecode.LessEqual:
+		op = func(l, r int) (Object, bool) { return BooleanObject(l <= r), true }
+	case bytecode.GreaterEqual:
+		op = func(l, r int) (Object, bool) { return BooleanObject(l >= r), true }
+	}
+
+	return func(t *Thread, cf *CallFrame) {
+		stack := &t.Stack
+		if l, ok := stack.at(1).(IntegerObject); ok {
+			if r, ok := stack.at(0).(IntegerObject); ok {
+				if result, ok := op(int(l), int(r)); ok {
+					stack.Discard()
+					stack.setTop(result)
+					return
+				}
+			}
+		}
+		t.binaryOperator(cf, methodName, site)
+	}
+}
+
+// isFalsy returns true for the values that conditions treat as false
+func isFalsy(v Object) bool {
+	switch v := v.(type) {
+	case BooleanObject:
+		return !bool(v)
+	case *NilObject:
+		return true
+	}
+	return false
+}
@@ -1,6 +1,28 @@
 package vm
 
-import "os"
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+)
+
+// orderedMap is a map decoded from a document, which keeps its keys in the order they appear
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{values: map[string]interface{}{}}
+}
+
+// set sets the value of a key, adding the key to the end of the order if it is new
+func (m *orderedMap) set(key string, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
 
 // InitObjectFromGoType returns an object that can be used from Lito
 func (vm *VM) InitObjectFromGoType(value interface{}) Object {
@@ -11,6 +33,9 @@ func (vm *VM) InitObjectFromGoType(value interface{}) Object {
 	case int:
 		return IntegerObject(val)
 
+	case *big.Int:
+		return integerResult(val)
+
 	case int64:
 		return IntegerObject(int(val))
 
@@ -20,10 +45,22 @@ func (vm *VM) InitObjectFromGoType(value interface{}) Object {
 	case float64:
 		return FloatObject(val)
 
+	case json.Number:
+		// Keep integers as integers, only falling back to floats
+		// when the number has a fraction, exponent or overflows an Integer
+		if i, err := val.Int64(); err == nil {
+			return IntegerObject(int(i))
+		}
+		if i, ok := parseBigInteger(val.String(), 10); ok {
+			return i
+		}
+		f, _ := val.Float64()
+		return FloatObject(f)
+
 	case []uint8: // also handles []byte
 		bytes := make([]byte, len(val))
 		copy(bytes, val)
-		return StringObject(bytes)
+		return vm.InitBytesObject(bytes)
 
 	case string:
 		return StringObject(val)
@@ -45,6 +82,13 @@ func (vm *VM) InitObjectFromGoType(value interface{}) Object {
 		}
 		return InitHashObject(pairs)
 
+	case *orderedMap:
+		h := newHashObject()
+		for _, k := range val.keys {
+			h.set(k, vm.InitObjectFromGoType(val.values[k]))
+		}
+		return h
+
 	case *os.File:
 		return initFileObject(vm, val)
 
@@ -72,12 +116,18 @@ func (vm *VM) InitGoTypeFromObject(value Object) interface{} {
 	case IntegerObject:
 		return int(val)
 
+	case *BigIntegerObject:
+		return val.value
+
 	case FloatObject:
 		return float64(val)
 
 	case StringObject:
 		return string(val)
 
+	case *BytesObject:
+		return val.bytes
+
 	case BooleanObject:
 		return bool(val)
 
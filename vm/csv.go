@@ -0,0 +1,316 @@
+package vm
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+// csvOptions holds the options common to the CSV methods
+type csvOptions struct {
+	headers   bool
+	separator rune
+}
+
+var csvClassMethods = []*BuiltinMethodObject{
+	{
+		Name:      "new",
+		Fn:        NoSuchMethod("new"),
+		Primitive: true,
+	},
+	{
+		// Parses a CSV string, returning an Array of rows.
+		// Pass `headers: true` to treat the first row as a header, in which case
+		// each row is returned as a Hash keyed by the header names.
+		// A different separator can be given with `separator:`.
+		Name: "parse",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			options, args := t.keywordArguments(args)
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			s, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+			}
+
+			opts, err := csvParseOptions(t, options)
+			if err != nil {
+				return err
+			}
+
+			var rows []Object
+			if err := csvReadRows(t, strings.NewReader(string(s)), opts, func(row Object) bool {
+				rows = append(rows, row)
+				return true
+			}); err != nil {
+				return err
+			}
+			return InitArrayObject(rows)
+		},
+	},
+	{
+		// Reads all of the rows from a File, taking the same options as `parse`.
+		Name: "read",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			options, args := t.keywordArguments(args)
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			f, ok := args[0].(*FileObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.FileClass, args[0].Class().Name)
+			}
+
+			opts, err := csvParseOptions(t, options)
+			if err != nil {
+				return err
+			}
+
+			var rows []Object
+			if err := csvReadRows(t, f.File, opts, func(row Object) bool {
+				rows = append(rows, row)
+				return true
+			}); err != nil {
+				return err
+			}
+			return InitArrayObject(rows)
+		},
+	},
+	{
+		// Reads the rows from a File one at a time, yielding each to the block.
+		// Takes the same options as `parse`, and returns the number of rows read.
+		Name: "each",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			options, args := t.keywordArguments(args)
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			f, ok := args[0].(*FileObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.FileClass, args[0].Class().Name)
+			}
+
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			opts, err := csvParseOptions(t, options)
+			if err != nil {
+				return err
+			}
+
+			count := 0
+			if err := csvReadRows(t, f.File, opts, func(row Object) bool {
+				count++
+				if blockFrame.IsEmpty() {
+					return true
+				}
+				t.Yield(blockFrame, row)
+				return !blockFrame.IsRemoved()
+			}); err != nil {
+				return err
+			}
+			return IntegerObject(count)
+		},
+	},
+	{
+		// Generates a CSV string from an Array of rows.
+		// Rows may be Arrays or Hashes. If the first row is a Hash, its keys are written
+		// as a header, and the values of every row are written in the same order.
+		Name: "generate",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			options, args := t.keywordArguments(args)
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			opts, err := csvParseOptions(t, options)
+			if err != nil {
+				return err
+			}
+
+			var out bytes.Buffer
+			if err := csvWriteRows(t, &out, args[0], opts); err != nil {
+				return err
+			}
+			return StringObject(out.String())
+		},
+	},
+	{
+		// Writes an Array of rows to a File, in the same way as `generate`.
+		// Returns the number of rows written, not including any header.
+		Name: "write",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			options, args := t.keywordArguments(args)
+			if len(args) != 2 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 2, len(args))
+			}
+
+			f, ok := args[0].(*FileObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.FileClass, args[0].Class().Name)
+			}
+
+			opts, err := csvParseOptions(t, options)
+			if err != nil {
+				return err
+			}
+
+			if err := csvWriteRows(t, f.File, args[1], opts); err != nil {
+				return err
+			}
+			return IntegerObject(len(args[1].(*ArrayObject).Elements))
+		},
+	},
+}
+
+var csvInstanceMethods = []*BuiltinMethodObject{}
+
+func initCSVClass(vm *VM) {
+	class := vm.InitClass("CSV").
+		ClassMethods(csvClassMethods).
+		InstanceMethods(csvInstanceMethods)
+	vm.TopLevelClass(classes.ObjectClass).SetClassConstant(class)
+}
+
+// csvParseOptions reads the `headers:` and `separator:` options
+func csvParseOptions(t *Thread, options map[string]Object) (csvOptions, *Error) {
+	opts := csvOptions{separator: ','}
+	for name, value := range options {
+		switch name {
+		case "headers":
+			opts.headers = value.IsTruthy()
+		case "separator":
+			s, ok := value.(StringObject)
+			if !ok {
+				return opts, t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, value.Class().Name)
+			}
+			r, size := utf8.DecodeRuneInString(string(s))
+			if size == 0 || size != len(s) {
+				return opts, t.vm.InitErrorObject(t, errors.ArgumentError, "Expect separator to be a single character. got: %s", s.Inspect(t))
+			}
+			opts.separator = r
+		default:
+			return opts, t.vm.InitErrorObject(t, errors.ArgumentError, "Unknown option: %s", name)
+		}
+	}
+	return opts, nil
+}
+
+// csvReadRows reads each row from r, calling fn with the row until it returns false
+func csvReadRows(t *Thread, r io.Reader, opts csvOptions, fn func(row Object) bool) *Error {
+	reader := csv.NewReader(r)
+	reader.Comma = opts.separator
+	// Allow rows to have differing numbers of fields
+	reader.FieldsPerRecord = -1
+
+	var headers []string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return t.vm.InitErrorObject(t, errors.CSVError, "Can't parse CSV: %s", err.Error())
+		}
+
+		if opts.headers && headers == nil {
+			headers = record
+			continue
+		}
+
+		var row Object
+		if headers != nil {
+			pairs := newHashObject()
+			for i, h := range headers {
+				if i < len(record) {
+					pairs.set(h, StringObject(record[i]))
+				} else {
+					pairs.set(h, NIL)
+				}
+			}
+			row = pairs
+		} else {
+			fields := make([]Object, len(record))
+			for i, field := range record {
+				fields[i] = StringObject(field)
+			}
+			row = InitArrayObject(fields)
+		}
+
+		if !fn(row) {
+			return nil
+		}
+	}
+}
+
+// csvWriteRows writes an Array of Array or Hash rows to w
+func csvWriteRows(t *Thread, w io.Writer, rows Object, opts csvOptions) *Error {
+	arr, ok := rows.(*ArrayObject)
+	if !ok {
+		return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.ArrayClass, rows.Class().Name)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = opts.separator
+
+	var headers []string
+	if len(arr.Elements) > 0 {
+		if h, ok := arr.Elements[0].(*HashObject); ok {
+			headers = h.keys()
+			if err := writer.Write(headers); err != nil {
+				return t.vm.InitErrorObject(t, errors.IOError, err.Error())
+			}
+		}
+	}
+
+	for _, elem := range arr.Elements {
+		var record []string
+		switch row := elem.(type) {
+		case *ArrayObject:
+			record = make([]string, len(row.Elements))
+			for i, field := range row.Elements {
+				record[i] = csvField(t, field)
+			}
+		case *HashObject:
+			if headers == nil {
+				return t.vm.InitErrorObject(t, errors.TypeError, "Expect all rows to be Arrays when the first row is an Array. got: Hash")
+			}
+			record = make([]string, len(headers))
+			for i, h := range headers {
+				if field, ok := row.Pairs[h]; ok {
+					record[i] = csvField(t, field)
+				}
+			}
+		default:
+			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Array or Hash rows", elem.Class().Name)
+		}
+		if err := writer.Write(record); err != nil {
+			return t.vm.InitErrorObject(t, errors.IOError, err.Error())
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return t.vm.InitErrorObject(t, errors.IOError, err.Error())
+	}
+	return nil
+}
+
+// csvField returns the text written for a single field, with nil as an empty field
+func csvField(t *Thread, field Object) string {
+	if field == NIL {
+		return ""
+	}
+	return field.ToString(t)
+}
@@ -0,0 +1,524 @@
+package vm
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+// DecimalObject is an exact decimal number with a fixed number of digits after the
+// decimal point, its scale. Results of arithmetic take the larger scale of the operands,
+// rounding with the receiver's rounding mode when digits have to be dropped.
+type DecimalObject struct {
+	BaseObj
+	value    *big.Int
+	scale    int
+	rounding string
+}
+
+// defaultRounding is the rounding mode used when none is given
+const defaultRounding = "half_up"
+
+// roundingModes are the supported ways of rounding a Decimal
+var roundingModes = map[string]bool{
+	"up":        true,
+	"down":      true,
+	"ceiling":   true,
+	"floor":     true,
+	"half_up":   true,
+	"half_down": true,
+	"half_even": true,
+}
+
+var decimalPattern = regexp.MustCompile(`^([+-]?)(\d*)(?:\.(\d*))?(?:[eE]([+-]?\d+))?$`)
+
+var decimalClassMethods = []*BuiltinMethodObject{
+	{
+		// Creates a Decimal from a String, Integer, Float, Rational or Decimal.
+		// An optional scale rounds the value to that many decimal places,
+		// using the optional rounding mode, which defaults to "half_up".
+		Name: "new",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) < 1 || len(args) > 3 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentRange, 1, 3, len(args))
+			}
+
+			rounding := defaultRounding
+			if len(args) == 3 {
+				mode, err := roundingMode(t, args[2])
+				if err != nil {
+					return err
+				}
+				rounding = mode
+			}
+
+			scale := -1
+			if len(args) > 1 {
+				s, ok := args[1].(IntegerObject)
+				if !ok {
+					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 2, classes.IntegerClass, args[1].Class().Name)
+				}
+				if s < 0 {
+					return t.vm.InitErrorObject(t, errors.ArgumentError, errors.NegativeSecondValue, int(s))
+				}
+				scale = int(s)
+			}
+
+			var value *big.Int
+			var valueScale int
+			switch arg := args[0].(type) {
+			case StringObject:
+				v, s, ok := parseDecimal(strings.TrimSpace(string(arg)))
+				if !ok {
+					return t.vm.InitErrorObject(t, errors.ArgumentError, errors.InvalidNumericString, string(arg))
+				}
+				value, valueScale = v, s
+			case *RationalObject:
+				if scale < 0 {
+					return t.vm.InitErrorObject(t, errors.ArgumentError, "Expect a scale to convert a Rational to a Decimal")
+				}
+				value = roundQuotient(new(big.Int).Mul(arg.value.Num(), pow10(scale)), arg.value.Denom(), rounding)
+				valueScale = scale
+			default:
+				v, s, ok := decimalValue(arg)
+				if !ok {
+					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 1, "Numeric or String", args[0].Class().Name)
+				}
+				value, valueScale = v, s
+			}
+
+			if scale < 0 {
+				scale = valueScale
+			}
+			value = rescaleDecimal(value, valueScale, scale, rounding)
+			return t.vm.initDecimalObject(value, scale, rounding)
+		},
+	},
+}
+
+var decimalInstanceMethods = []*BuiltinMethodObject{
+	{
+		Name: "+",
+		Fn: decimalArithmetic("+", func(l, r *big.Int, scale int, rounding string) (*big.Int, bool) {
+			return new(big.Int).Add(l, r), true
+		}),
+	},
+	{
+		Name: "%",
+		Fn: decimalArithmetic("%", func(l, r *big.Int, scale int, rounding string) (*big.Int, bool) {
+			if r.Sign() == 0 {
+				return nil, false
+			}
+			return new(big.Int).Rem(l, r), true
+		}),
+	},
+	{
+		Name: "-",
+		Fn: decimalArithmetic("-", func(l, r *big.Int, scale int, rounding string) (*big.Int, bool) {
+			return new(big.Int).Sub(l, r), true
+		}),
+	},
+	{
+		Name: "*",
+		Fn: decimalArithmetic("*", func(l, r *big.Int, scale int, rounding string) (*big.Int, bool) {
+			return roundQuotient(new(big.Int).Mul(l, r), pow10(scale), rounding), true
+		}),
+	},
+	{
+		// Raises the Decimal to an Integer power, keeping its scale
+		Name: "**",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			d := receiver.(*DecimalObject)
+			n, ok := args[0].(IntegerObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.IntegerClass, args[0].Class().Name)
+			}
+
+			exp := int(n)
+			if exp < 0 {
+				exp = -exp
+			}
+			num := new(big.Int).Exp(d.value, big.NewInt(int64(exp)), nil)
+			den := pow10(d.scale * exp)
+			if n < 0 {
+				if num.Sign() == 0 {
+					return t.vm.InitErrorObject(t, errors.ZeroDivisionError, errors.DividedByZero)
+				}
+				num, den = den, num
+			}
+			value := roundQuotient(new(big.Int).Mul(num, pow10(d.scale)), den, d.rounding)
+			return t.vm.initDecimalObject(value, d.scale, d.rounding)
+		},
+	},
+	{
+		Name: "/",
+		Fn: decimalArithmetic("/", func(l, r *big.Int, scale int, rounding string) (*big.Int, bool) {
+			if r.Sign() == 0 {
+				return nil, false
+			}
+			return roundQuotient(new(big.Int).Mul(l, pow10(scale)), r, rounding), true
+		}),
+	},
+	{
+		Name: ">",
+		Fn: decimalCompare(">", func(c int) bool {
+			return c > 0
+		}),
+	},
+	{
+		Name: ">=",
+		Fn: decimalCompare(">=", func(c int) bool {
+			return c >= 0
+		}),
+	},
+	{
+		Name: "<",
+		Fn: decimalCompare("<", func(c int) bool {
+			return c < 0
+		}),
+	},
+	{
+		Name: "<=",
+		Fn: decimalCompare("<=", func(c int) bool {
+			return c <= 0
+		}),
+	},
+	{
+		Name: "abs",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			d := receiver.(*DecimalObject)
+			return t.vm.initDecimalObject(new(big.Int).Abs(d.value), d.scale, d.rounding)
+		},
+	},
+	{
+		// Converts an Integer or Float to a Decimal so it can be used with the receiver's operators
+		Name: "coerce",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			value, scale, ok := decimalValue(args[0])
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+			}
+			d := receiver.(*DecimalObject)
+			return InitArrayObject([]Object{t.vm.initDecimalObject(value, scale, d.rounding), d})
+		},
+	},
+	{
+		Name: "float",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return FloatObject(receiver.(*DecimalObject).floatValue())
+		},
+	},
+	{
+		// Returns the integer part of the Decimal
+		Name: "int",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			d := receiver.(*DecimalObject)
+			return integerResult(new(big.Int).Quo(d.value, pow10(d.scale)))
+		},
+	},
+	{
+		Name: "rational",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return t.vm.initRationalObject(receiver.(*DecimalObject).rat())
+		},
+	},
+	{
+		// Rounds to the given scale, or to an integral Decimal, using the given rounding mode
+		// or the Decimal's own
+		Name: "round",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) > 2 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 2, len(args))
+			}
+			d := receiver.(*DecimalObject)
+
+			scale := 0
+			if len(args) > 0 {
+				s, ok := args[0].(IntegerObject)
+				if !ok {
+					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 1, classes.IntegerClass, args[0].Class().Name)
+				}
+				if s < 0 {
+					return t.vm.InitErrorObject(t, errors.ArgumentError, errors.NegativeValue, int(s))
+				}
+				scale = int(s)
+			}
+			rounding := d.rounding
+			if len(args) > 1 {
+				mode, err := roundingMode(t, args[1])
+				if err != nil {
+					return err
+				}
+				rounding = mode
+			}
+			return t.vm.initDecimalObject(rescaleDecimal(d.value, d.scale, scale, rounding), scale, d.rounding)
+		},
+	},
+	{
+		Name: "rounding",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return StringObject(receiver.(*DecimalObject).rounding)
+		},
+	},
+	{
+		Name: "scale",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return IntegerObject(receiver.(*DecimalObject).scale)
+		},
+	},
+	{
+		Name: "string",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return StringObject(receiver.ToString(t))
+		},
+	},
+}
+
+func (vm *VM) initDecimalObject(value *big.Int, scale int, rounding string) *DecimalObject {
+	return &DecimalObject{
+		BaseObj:  BaseObj{class: vm.TopLevelClass(classes.DecimalClass)},
+		value:    value,
+		scale:    scale,
+		rounding: rounding,
+	}
+}
+
+func initDecimalClass(vm *VM) *RClass {
+	return vm.InitClass(classes.DecimalClass).
+		ClassMethods(decimalClassMethods).
+		InstanceMethods(decimalInstanceMethods)
+}
+
+// parseDecimal parses a decimal string, such as "-12.50" or "1.5e3",
+// into its unscaled value and scale
+func parseDecimal(s string) (*big.Int, int, bool) {
+	m := decimalPattern.FindStringSubmatch(s)
+	if m == nil || m[2]+m[3] == "" {
+		return nil, 0, false
+	}
+
+	value, _ := new(big.Int).SetString(m[1]+m[2]+m[3], 10)
+	scale := len(m[3])
+	if m[4] != "" {
+		exp, err := strconv.Atoi(m[4])
+		if err != nil {
+			return nil, 0, false
+		}
+		scale -= exp
+	}
+	if scale < 0 {
+		value.Mul(value, pow10(-scale))
+		scale = 0
+	}
+	return value, scale, true
+}
+
+// decimalValue returns the unscaled value and scale of a Decimal, Integer, BigInteger or Float
+func decimalValue(obj Object) (*big.Int, int, bool) {
+	switch obj := obj.(type) {
+	case *DecimalObject:
+		return obj.value, obj.scale, true
+	case IntegerObject, *BigIntegerObject:
+		v, _ := bigIntValue(obj)
+		return v, 0, true
+	case FloatObject:
+		f := float64(obj)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, 0, false
+		}
+		return parseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	return nil, 0, false
+}
+
+// roundingMode validates a rounding mode argument
+func roundingMode(t *Thread, arg Object) (string, *Error) {
+	mode, ok := arg.(StringObject)
+	if !ok {
+		return "", t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, arg.Class().Name)
+	}
+	if !roundingModes[string(mode)] {
+		return "", t.vm.InitErrorObject(t, errors.ArgumentError, "Unknown rounding mode: %s", string(mode))
+	}
+	return string(mode), nil
+}
+
+// pow10 returns 10 to the power of n
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// rescaleDecimal changes the scale of an unscaled value, rounding if digits are dropped
+func rescaleDecimal(value *big.Int, from, to int, rounding string) *big.Int {
+	if to >= from {
+		return new(big.Int).Mul(value, pow10(to-from))
+	}
+	return roundQuotient(value, pow10(from-to), rounding)
+}
+
+// roundQuotient divides num by den, rounding the result with the given rounding mode
+func roundQuotient(num, den *big.Int, rounding string) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	sign := num.Sign() * den.Sign()
+	// Compare twice the remainder with the divisor, to find which half the remainder is in
+	half := new(big.Int).Lsh(new(big.Int).Abs(r), 1).Cmp(new(big.Int).Abs(den))
+
+	var away bool
+	switch rounding {
+	case "up":
+		away = true
+	case "ceiling":
+		away = sign > 0
+	case "floor":
+		away = sign < 0
+	case "half_up":
+		away = half >= 0
+	case "half_down":
+		away = half > 0
+	case "half_even":
+		away = half > 0 || half == 0 && q.Bit(0) == 1
+	}
+	if away {
+		q.Add(q, big.NewInt(int64(sign)))
+	}
+	return q
+}
+
+// decimalArithmetic returns a method applying the named operator to a Decimal and a number
+// that can be converted to a Decimal. The operator is given both values at the larger of
+// their scales, and returns false if the right operand is zero where that is not allowed.
+func decimalArithmetic(name string, op func(l, r *big.Int, scale int, rounding string) (*big.Int, bool)) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 1 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+		}
+		d := receiver.(*DecimalObject)
+
+		value, valueScale, ok := decimalValue(args[0])
+		if !ok {
+			return coerceOperator(t, name, receiver, args[0])
+		}
+		scale := d.scale
+		if valueScale > scale {
+			scale = valueScale
+		}
+
+		l := rescaleDecimal(d.value, d.scale, scale, d.rounding)
+		r := rescaleDecimal(value, valueScale, scale, d.rounding)
+		result, ok := op(l, r, scale, d.rounding)
+		if !ok {
+			return t.vm.InitErrorObject(t, errors.ZeroDivisionError, errors.DividedByZero)
+		}
+		return t.vm.initDecimalObject(result, scale, d.rounding)
+	}
+}
+
+// decimalCompare returns a method comparing a Decimal with a number
+func decimalCompare(name string, test func(c int) bool) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 1 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+		}
+		c, ok := receiver.(*DecimalObject).compare(args[0])
+		if !ok {
+			return coerceOperator(t, name, receiver, args[0])
+		}
+		return BooleanObject(test(c))
+	}
+}
+
+// compare returns -1, 0 or 1 as d is less than, equal to or greater than the number.
+// It returns false if the object cannot be converted to a Decimal.
+func (d *DecimalObject) compare(obj Object) (int, bool) {
+	if r, ok := obj.(*RationalObject); ok {
+		return d.rat().Cmp(r.value), true
+	}
+	value, scale, ok := decimalValue(obj)
+	if !ok {
+		return 0, false
+	}
+	return d.rat().Cmp(new(big.Rat).SetFrac(value, pow10(scale))), true
+}
+
+// rat returns the exact value of the Decimal as a fraction
+func (d *DecimalObject) rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.value, pow10(d.scale))
+}
+
+// Value returns the Decimal as a string
+func (d *DecimalObject) Value() interface{} {
+	return d.ToString(nil)
+}
+
+func (d *DecimalObject) floatValue() float64 {
+	f, _ := d.rat().Float64()
+	return f
+}
+
+// EqualTo returns true if the object is a number with the same value
+func (d *DecimalObject) EqualTo(with Object) bool {
+	c, ok := d.compare(with)
+	return ok && c == 0
+}
+
+// ToString returns the Decimal with all the digits of its scale
+func (d *DecimalObject) ToString(t *Thread) string {
+	digits := new(big.Int).Abs(d.value).String()
+	if d.scale > 0 {
+		if len(digits) <= d.scale {
+			digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-d.scale] + "." + digits[len(digits)-d.scale:]
+	}
+	if d.value.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// Inspect returns the Decimal with its class name
+func (d *DecimalObject) Inspect(t *Thread) string {
+	return fmt.Sprintf("#<Decimal %s>", d.ToString(t))
+}
+
+// ToJSON returns the Decimal as a JSON number with all of its digits
+func (d *DecimalObject) ToJSON(t *Thread) string {
+	return d.ToString(t)
+}
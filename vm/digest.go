@@ -0,0 +1,110 @@
+package vm
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"strings"
+
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+// digestAlgorithms maps the names accepted by `Digest hmac` to their hash constructors
+var digestAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+var digestClassMethods = []*BuiltinMethodObject{
+	{
+		Name:      "new",
+		Fn:        NoSuchMethod("new"),
+		Primitive: true,
+	},
+	{
+		Name:      "md5",
+		Fn:        digestMethod(md5.New),
+		Primitive: true,
+	},
+	{
+		Name:      "sha1",
+		Fn:        digestMethod(sha1.New),
+		Primitive: true,
+	},
+	{
+		Name:      "sha256",
+		Fn:        digestMethod(sha256.New),
+		Primitive: true,
+	},
+	{
+		Name:      "sha512",
+		Fn:        digestMethod(sha512.New),
+		Primitive: true,
+	},
+	{
+		// Returns the HMAC of the data with the given key, using the named algorithm,
+		// e.g. `Digest hmac(:sha256, key, data)`
+		Name: "hmac",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 3 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 3, len(args))
+			}
+
+			name, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 1, classes.StringClass, args[0].Class().Name)
+			}
+			newHash, ok := digestAlgorithms[strings.ToLower(string(name))]
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, "Unknown digest algorithm: %s", string(name))
+			}
+
+			key, ok := bytesValue(args[1])
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 2, "String or Bytes", args[1].Class().Name)
+			}
+			data, ok := bytesValue(args[2])
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 3, "String or Bytes", args[2].Class().Name)
+			}
+
+			mac := hmac.New(newHash, key)
+			mac.Write(data)
+			return t.vm.InitBytesObject(mac.Sum(nil))
+		},
+		Primitive: true,
+	},
+}
+
+var digestInstanceMethods = []*BuiltinMethodObject{}
+
+func initDigestClass(vm *VM) {
+	class := vm.InitClass("Digest").
+		ClassMethods(digestClassMethods).
+		InstanceMethods(digestInstanceMethods)
+	vm.TopLevelClass(classes.ObjectClass).SetClassConstant(class)
+}
+
+// digestMethod returns a method that hashes a String or Bytes argument, returning the digest as Bytes
+func digestMethod(newHash func() hash.Hash) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 1 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+		}
+
+		data, ok := bytesValue(args[0])
+		if !ok {
+			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "String or Bytes", args[0].Class().Name)
+		}
+
+		h := newHash()
+		h.Write(data)
+		return t.vm.InitBytesObject(h.Sum(nil))
+	}
+}
@@ -0,0 +1,99 @@
+package vm
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+var base64ClassMethods = []*BuiltinMethodObject{
+	{
+		Name:      "new",
+		Fn:        NoSuchMethod("new"),
+		Primitive: true,
+	},
+	{
+		Name:      "encode",
+		Fn:        encodeMethod(base64.StdEncoding.EncodeToString),
+		Primitive: true,
+	},
+	{
+		Name:      "decode",
+		Fn:        decodeMethod("base64", base64.StdEncoding.DecodeString),
+		Primitive: true,
+	},
+	{
+		Name:      "url_encode",
+		Fn:        encodeMethod(base64.URLEncoding.EncodeToString),
+		Primitive: true,
+	},
+	{
+		// Decodes URL safe base64, with or without padding
+		Name: "url_decode",
+		Fn: decodeMethod("base64", func(s string) ([]byte, error) {
+			return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+		}),
+		Primitive: true,
+	},
+}
+
+var hexClassMethods = []*BuiltinMethodObject{
+	{
+		Name:      "new",
+		Fn:        NoSuchMethod("new"),
+		Primitive: true,
+	},
+	{
+		Name:      "encode",
+		Fn:        encodeMethod(hex.EncodeToString),
+		Primitive: true,
+	},
+	{
+		Name:      "decode",
+		Fn:        decodeMethod("hex", hex.DecodeString),
+		Primitive: true,
+	},
+}
+
+func initEncodingClasses(vm *VM) {
+	vm.TopLevelClass(classes.ObjectClass).SetClassConstant(vm.InitClass("Base64").ClassMethods(base64ClassMethods))
+	vm.TopLevelClass(classes.ObjectClass).SetClassConstant(vm.InitClass("Hex").ClassMethods(hexClassMethods))
+}
+
+// encodeMethod returns a method that encodes a String or Bytes argument as a String
+func encodeMethod(encode func([]byte) string) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 1 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+		}
+
+		data, ok := bytesValue(args[0])
+		if !ok {
+			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "String or Bytes", args[0].Class().Name)
+		}
+		return StringObject(encode(data))
+	}
+}
+
+// decodeMethod returns a method that decodes a String argument into Bytes
+func decodeMethod(name string, decode func(string) ([]byte, error)) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 1 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+		}
+
+		s, ok := args[0].(StringObject)
+		if !ok {
+			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+		}
+
+		data, err := decode(string(s))
+		if err != nil {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, "Invalid %s: %s", name, err.Error())
+		}
+		return t.vm.InitBytesObject(data)
+	}
+}
@@ -0,0 +1,795 @@
+package vm
+
+import (
+	"sort"
+
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+// enumerableModule provides the collection methods to any class which defines each and includes it.
+// The values yielded by each are passed on to the blocks given to these methods, and
+// where several values are yielded together they are collected as an Array.
+var enumerableModule *RClass
+
+var enumerableMethods = []*BuiltinMethodObject{
+	{
+		Name: "all?",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+
+			result := TRUE
+			enumerateTest(t, receiver, func(truthy bool) bool {
+				if !truthy {
+					result = FALSE
+				}
+				return truthy
+			})
+			return result
+		},
+	},
+	{
+		Name: "any?",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+
+			result := FALSE
+			enumerateTest(t, receiver, func(truthy bool) bool {
+				if truthy {
+					result = TRUE
+				}
+				return !truthy
+			})
+			return result
+		},
+	},
+	{
+		// Counts the values, the values equal to the argument, or the values for which the block is truthy
+		Name: "count",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) > 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
+			}
+
+			var count int
+			blockFrame := t.GetBlock()
+			enumerate(t, receiver, func(values []Object) bool {
+				switch {
+				case len(args) == 1:
+					if args[0].EqualTo(enumValue(values)) {
+						count++
+					}
+				case blockFrame != nil:
+					if t.Yield(blockFrame, values...).IsTruthy() {
+						count++
+					}
+					return !blockFrame.IsRemoved()
+				default:
+					count++
+				}
+				return true
+			})
+			return IntegerObject(count)
+		},
+	},
+	{
+		// Returns the values after the first n
+		Name: "drop",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			n, err := enumCount(t, args)
+			if err != nil {
+				return err
+			}
+
+			var result []Object
+			enumerate(t, receiver, func(values []Object) bool {
+				if n > 0 {
+					n--
+				} else {
+					result = append(result, enumValue(values))
+				}
+				return true
+			})
+			return InitArrayObject(result)
+		},
+	},
+	{
+		// Returns the values from the first one for which the block is falsy
+		Name: "drop_while",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			var result []Object
+			dropping := true
+			enumerate(t, receiver, func(values []Object) bool {
+				if dropping && !t.Yield(blockFrame, values...).IsTruthy() {
+					dropping = false
+				}
+				if !dropping {
+					result = append(result, enumValue(values))
+				}
+				return !blockFrame.IsRemoved()
+			})
+			return InitArrayObject(result)
+		},
+	},
+	{
+		// Yields each run of n consecutive values as an Array. Without a block, returns the runs.
+		Name: "each_cons",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			n, err := enumCount(t, args)
+			if err != nil {
+				return err
+			}
+			if n <= 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.NegativeValue, n)
+			}
+
+			var window, result []Object
+			blockFrame := t.GetBlock()
+			enumerate(t, receiver, func(values []Object) bool {
+				window = append(window, enumValue(values))
+				if len(window) > n {
+					window = window[1:]
+				}
+				if len(window) < n {
+					return true
+				}
+				return enumEmit(t, blockFrame, &result, append([]Object(nil), window...))
+			})
+			return enumResult(receiver, blockFrame, result)
+		},
+	},
+	{
+		// Yields the values in Arrays of n, the last of which may be shorter. Without a block, returns the Arrays.
+		Name: "each_slice",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			n, err := enumCount(t, args)
+			if err != nil {
+				return err
+			}
+			if n <= 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.NegativeValue, n)
+			}
+
+			var slice, result []Object
+			blockFrame := t.GetBlock()
+			more := enumerate(t, receiver, func(values []Object) bool {
+				slice = append(slice, enumValue(values))
+				if len(slice) < n {
+					return true
+				}
+				s := slice
+				slice = nil
+				return enumEmit(t, blockFrame, &result, s)
+			})
+			if more && len(slice) > 0 {
+				enumEmit(t, blockFrame, &result, slice)
+			}
+			return enumResult(receiver, blockFrame, result)
+		},
+	},
+	{
+		// Yields the values along with their index
+		Name: "each_with_index",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			var i int
+			enumerate(t, receiver, func(values []Object) bool {
+				t.Yield(blockFrame, enumValue(values), IntegerObject(i))
+				i++
+				return !blockFrame.IsRemoved()
+			})
+			return receiver
+		},
+	},
+	{
+		Name: "filter",
+		Fn:   enumFilter(true),
+	},
+	{
+		// Returns the first value for which the block is truthy, or nil
+		Name: "find",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			var found Object = NIL
+			enumerate(t, receiver, func(values []Object) bool {
+				if t.Yield(blockFrame, values...).IsTruthy() {
+					found = enumValue(values)
+					return false
+				}
+				return !blockFrame.IsRemoved()
+			})
+			return found
+		},
+	},
+	{
+		// Returns the first value, or an Array of the first n values
+		Name: "first",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) > 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
+			}
+
+			if len(args) == 0 {
+				var first Object = NIL
+				enumerate(t, receiver, func(values []Object) bool {
+					first = enumValue(values)
+					return false
+				})
+				return first
+			}
+
+			n, err := enumCount(t, args)
+			if err != nil {
+				return err
+			}
+			result := []Object{}
+			if n > 0 {
+				enumerate(t, receiver, func(values []Object) bool {
+					result = append(result, enumValue(values))
+					return len(result) < n
+				})
+			}
+			return InitArrayObject(result)
+		},
+	},
+	{
+		// Maps each value with the block, concatenating the results which are Arrays
+		Name: "flat_map",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			var result []Object
+			enumerate(t, receiver, func(values []Object) bool {
+				switch r := t.Yield(blockFrame, values...).(type) {
+				case *ArrayObject:
+					result = append(result, r.Elements...)
+				default:
+					result = append(result, r)
+				}
+				return !blockFrame.IsRemoved()
+			})
+			return InitArrayObject(result)
+		},
+	},
+	{
+		// Returns a Hash of the block results, as keys, to Arrays of the values with that result.
+		// Results which are different but have the same Hash key, such as 1 and "1", raise an ArgumentError.
+		Name: "group_by",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			groups := newHashObject()
+			owners := map[string]Object{}
+			var err *Error
+			enumerate(t, receiver, func(values []Object) bool {
+				var group string
+				if group, err = uniqueHashKey(t, owners, t.Yield(blockFrame, values...)); err != nil {
+					return false
+				}
+				items, ok := groups.Pairs[group].(*ArrayObject)
+				if !ok {
+					items = InitArrayObject(nil)
+					groups.set(group, items)
+				}
+				items.Elements = append(items.Elements, enumValue(values))
+				return !blockFrame.IsRemoved()
+			})
+			if err != nil {
+				return err
+			}
+			return groups
+		},
+	},
+	{
+		// Returns a Lazy pipeline over the values
+		Name: "lazy",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return newLazyObject(t.vm, receiver)
+		},
+	},
+	{
+		Name: "map",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			var result []Object
+			enumerate(t, receiver, func(values []Object) bool {
+				result = append(result, t.Yield(blockFrame, values...))
+				return !blockFrame.IsRemoved()
+			})
+			return InitArrayObject(result)
+		},
+	},
+	{
+		Name: "max",
+		Fn:   enumExtreme(false),
+	},
+	{
+		Name: "max_by",
+		Fn:   enumExtremeBy(false),
+	},
+	{
+		Name: "min",
+		Fn:   enumExtreme(true),
+	},
+	{
+		Name: "min_by",
+		Fn:   enumExtremeBy(true),
+	},
+	{
+		Name: "none?",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+
+			result := TRUE
+			enumerateTest(t, receiver, func(truthy bool) bool {
+				if truthy {
+					result = FALSE
+				}
+				return !truthy
+			})
+			return result
+		},
+	},
+	{
+		// Returns an Array of the values for which the block is truthy, and an Array of the rest
+		Name: "partition",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			var in, out []Object
+			enumerate(t, receiver, func(values []Object) bool {
+				if t.Yield(blockFrame, values...).IsTruthy() {
+					in = append(in, enumValue(values))
+				} else {
+					out = append(out, enumValue(values))
+				}
+				return !blockFrame.IsRemoved()
+			})
+			return InitArrayObject([]Object{InitArrayObject(in), InitArrayObject(out)})
+		},
+	},
+	{
+		// Combines the values with the block, starting from the argument if given, or else the first value
+		Name: "reduce",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) > 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
+			}
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			var acc Object
+			if len(args) == 1 {
+				acc = args[0]
+			}
+			enumerate(t, receiver, func(values []Object) bool {
+				if acc == nil {
+					acc = enumValue(values)
+					return true
+				}
+				acc = t.Yield(blockFrame, acc, enumValue(values))
+				return !blockFrame.IsRemoved()
+			})
+			if acc == nil {
+				return NIL
+			}
+			return acc
+		},
+	},
+	{
+		Name: "reject",
+		Fn:   enumFilter(false),
+	},
+	{
+		// Returns the values ordered by the result of the block
+		Name: "sort_by",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			var items, keys []Object
+			enumerate(t, receiver, func(values []Object) bool {
+				keys = append(keys, t.Yield(blockFrame, values...))
+				items = append(items, enumValue(values))
+				return !blockFrame.IsRemoved()
+			})
+
+			indexes := make([]int, len(items))
+			for i := range indexes {
+				indexes[i] = i
+			}
+			sort.SliceStable(indexes, func(i, j int) bool {
+				return objectLessThan(keys[indexes[i]], keys[indexes[j]])
+			})
+
+			result := make([]Object, len(items))
+			for i, index := range indexes {
+				result[i] = items[index]
+			}
+			return InitArrayObject(result)
+		},
+	},
+	{
+		// Adds up the values, or the block results, starting from the argument or 0
+		Name: "sum",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) > 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
+			}
+
+			var sum Object = IntegerObject(0)
+			if len(args) == 1 {
+				sum = args[0]
+			}
+			blockFrame := t.GetBlock()
+			enumerate(t, receiver, func(values []Object) bool {
+				value := enumValue(values)
+				if blockFrame != nil {
+					value = t.Yield(blockFrame, values...)
+				}
+				sum = t.CallMethod(sum, "+", value)
+				return blockFrame == nil || !blockFrame.IsRemoved()
+			})
+			return sum
+		},
+	},
+	{
+		// Returns the first n values
+		Name: "take",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			n, err := enumCount(t, args)
+			if err != nil {
+				return err
+			}
+
+			result := []Object{}
+			if n <= 0 {
+				return InitArrayObject(result)
+			}
+			enumerate(t, receiver, func(values []Object) bool {
+				result = append(result, enumValue(values))
+				return len(result) < n
+			})
+			return InitArrayObject(result)
+		},
+	},
+	{
+		// Returns the values before the first one for which the block is falsy
+		Name: "take_while",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			result := []Object{}
+			enumerate(t, receiver, func(values []Object) bool {
+				if !t.Yield(blockFrame, values...).IsTruthy() {
+					return false
+				}
+				result = append(result, enumValue(values))
+				return !blockFrame.IsRemoved()
+			})
+			return InitArrayObject(result)
+		},
+	},
+	{
+		Name: "to_a",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return InitArrayObject(enumToArray(t, receiver))
+		},
+	},
+	{
+		// Returns the values without duplicates, which are compared by the block result if given
+		Name: "uniq",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+
+			var result, seen []Object
+			blockFrame := t.GetBlock()
+			enumerate(t, receiver, func(values []Object) bool {
+				value := enumValue(values)
+				key := value
+				if blockFrame != nil {
+					key = t.Yield(blockFrame, values...)
+				}
+				for _, s := range seen {
+					if s.EqualTo(key) {
+						return true
+					}
+				}
+				seen = append(seen, key)
+				result = append(result, value)
+				return blockFrame == nil || !blockFrame.IsRemoved()
+			})
+			return InitArrayObject(result)
+		},
+	},
+	{
+		// Returns an Array of Arrays, each holding a value with the values at the same position
+		// in each of the arguments, or nil where an argument is shorter
+		Name: "zip",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			others := make([][]Object, len(args))
+			for i, arg := range args {
+				others[i] = enumToArray(t, arg)
+			}
+
+			var result []Object
+			enumerate(t, receiver, func(values []Object) bool {
+				i := len(result)
+				row := []Object{enumValue(values)}
+				for _, other := range others {
+					if i < len(other) {
+						row = append(row, other[i])
+					} else {
+						row = append(row, NIL)
+					}
+				}
+				result = append(result, InitArrayObject(row))
+				return true
+			})
+			return InitArrayObject(result)
+		},
+	},
+}
+
+func initEnumerableModule(vm *VM) *RClass {
+	enumerableModule = vm.InitModule(classes.EnumerableModule).
+		InstanceMethods(enumerableMethods)
+	return enumerableModule
+}
+
+// enumerate calls fn with the values of each iteration of the receiver until fn returns false,
+// and reports whether the iteration ran to the end. Arrays, Hashes and Ranges are iterated directly,
+// and any other receiver is iterated by calling its each method.
+func enumerate(t *Thread, receiver Object, fn func(values []Object) bool) bool {
+	switch r := receiver.(type) {
+	case *ArrayObject:
+		for _, el := range r.Elements {
+			if !fn([]Object{el}) {
+				return false
+			}
+		}
+	case *HashObject:
+		for _, k := range r.keys() {
+			if !fn([]Object{StringObject(k), r.Pairs[k]}) {
+				return false
+			}
+		}
+	case *SetObject:
+		for _, el := range r.elements() {
+			if !fn([]Object{el}) {
+				return false
+			}
+		}
+	case *RangeObject:
+		more := true
+		r.each(func(i int) bool {
+			more = fn([]Object{IntegerObject(i)})
+			return more
+		})
+		return more
+	default:
+		more := true
+		var blockFrame *CallFrame
+		blockFrame = newNativeBlockFrame(func(values []Object) Object {
+			if more && !fn(values) {
+				more = false
+				// Stops builtin each methods from yielding any more, and ends a Lito each at its yield
+				blockFrame.setAsRemoved()
+			}
+			return NIL
+		})
+		t.CallMethodWithBlock(receiver, "each", blockFrame)
+		return more
+	}
+	return true
+}
+
+// enumerateTest calls fn with the truthiness of each value, or the block result for each value
+// if there is a block, until fn returns false
+func enumerateTest(t *Thread, receiver Object, fn func(truthy bool) bool) {
+	blockFrame := t.GetBlock()
+	enumerate(t, receiver, func(values []Object) bool {
+		if blockFrame == nil {
+			return fn(enumValue(values).IsTruthy())
+		}
+		return fn(t.Yield(blockFrame, values...).IsTruthy()) && !blockFrame.IsRemoved()
+	})
+}
+
+// enumValue returns the values of an iteration as a single object
+func enumValue(values []Object) Object {
+	switch len(values) {
+	case 0:
+		return NIL
+	case 1:
+		return values[0]
+	default:
+		return InitArrayObject(values)
+	}
+}
+
+// enumToArray returns the values of the receiver
+func enumToArray(t *Thread, receiver Object) []Object {
+	result := []Object{}
+	enumerate(t, receiver, func(values []Object) bool {
+		result = append(result, enumValue(values))
+		return true
+	})
+	return result
+}
+
+// enumCount returns the single Integer argument
+func enumCount(t *Thread, args []Object) (int, *Error) {
+	if len(args) != 1 {
+		return 0, t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+	}
+	n, ok := args[0].(IntegerObject)
+	if !ok {
+		return 0, t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.IntegerClass, args[0].Class().Name)
+	}
+	return int(n), nil
+}
+
+// enumEmit yields the group of values to the block if there is one, or else adds it to the result
+func enumEmit(t *Thread, blockFrame *CallFrame, result *[]Object, group []Object) bool {
+	if blockFrame == nil {
+		*result = append(*result, InitArrayObject(group))
+		return true
+	}
+	t.Yield(blockFrame, InitArrayObject(group))
+	return !blockFrame.IsRemoved()
+}
+
+// enumResult returns the receiver if there was a block, or else the collected result
+func enumResult(receiver Object, blockFrame *CallFrame, result []Object) Object {
+	if blockFrame != nil {
+		return receiver
+	}
+	return InitArrayObject(result)
+}
+
+// enumFilter returns a method selecting the values for which the block result is truthy,
+// or if keep is false, the values for which it is not
+func enumFilter(keep bool) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 0 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+		}
+		blockFrame := t.GetBlock()
+		if blockFrame == nil {
+			return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+		}
+
+		result := []Object{}
+		enumerate(t, receiver, func(values []Object) bool {
+			if t.Yield(blockFrame, values...).IsTruthy() == keep {
+				result = append(result, enumValue(values))
+			}
+			return !blockFrame.IsRemoved()
+		})
+		return InitArrayObject(result)
+	}
+}
+
+// enumExtreme returns a method finding the smallest value, or the largest if min is false
+func enumExtreme(min bool) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 0 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+		}
+
+		var best Object
+		enumerate(t, receiver, func(values []Object) bool {
+			value := enumValue(values)
+			if best == nil || (min && objectLessThan(value, best)) || (!min && objectLessThan(best, value)) {
+				best = value
+			}
+			return true
+		})
+		if best == nil {
+			return NIL
+		}
+		return best
+	}
+}
+
+// enumExtremeBy returns a method finding the value with the smallest block result,
+// or the largest if min is false
+func enumExtremeBy(min bool) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 0 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+		}
+		blockFrame := t.GetBlock()
+		if blockFrame == nil {
+			return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+		}
+
+		var found, best Object
+		enumerate(t, receiver, func(values []Object) bool {
+			result := t.Yield(blockFrame, values...)
+			if best == nil || (min && objectLessThan(result, best)) || (!min && objectLessThan(best, result)) {
+				found, best = enumValue(values), result
+			}
+			return !blockFrame.IsRemoved()
+		})
+		if found == nil {
+			return NIL
+		}
+		return found
+	}
+}
@@ -153,6 +153,15 @@ func (e *Error) storeStackTraces(t *Thread) {
 	if !e.storedTraces {
 		e.stackTraces = nil
 	}
+	// Runs of the same line, as from recursion, are shown once with a count
+	var last string
+	repeats := 0
+	flushRepeats := func() {
+		if repeats > 0 {
+			e.stackTraces = append(e.stackTraces, fmt.Sprintf("... repeated %d more times", repeats))
+			repeats = 0
+		}
+	}
 	for i := t.callFrameStack.pointer - 1; i >= 0; i-- {
 		frame := t.callFrameStack.callFrames[i]
 
@@ -176,9 +185,16 @@ func (e *Error) storeStackTraces(t *Thread) {
 				sourceLine = sMap[cf.pc-1]
 			}
 			msg := fmt.Sprintf("from %s:%d", frame.FileName(), sourceLine)
+			if msg == last {
+				repeats++
+				continue
+			}
+			flushRepeats()
+			last = msg
 			e.stackTraces = append(e.stackTraces, msg)
 		}
 	}
+	flushRepeats()
 
 	e.storedTraces = true
 }
@@ -23,6 +23,24 @@ const (
 	ChannelCloseError = "ChannelCloseError"
 	// NotImplementedError is for features that have not been implemented
 	NotImplementedError = "NotImplementedError"
+	// JSONError is for malformed JSON input or output
+	JSONError = "JSONError"
+	// CSVError is for malformed CSV input
+	CSVError = "CSVError"
+	// YAMLError is for malformed YAML input or output
+	YAMLError = "YAMLError"
+	// TOMLError is for malformed TOML input or output
+	TOMLError = "TOMLError"
+	// KeyError is for a missing Hash key
+	KeyError = "KeyError"
+	// StopIteration is for reading past the end of a Generator
+	StopIteration = "StopIteration"
+	// TemplateError is for malformed templates
+	TemplateError = "TemplateError"
+	// StackOverflowError is for calls nested deeper than the call stack allows
+	StackOverflowError = "StackOverflowError"
+	// SyntaxError is for code which can't be compiled at runtime
+	SyntaxError = "SyntaxError"
 )
 
 //	Here defines different error message formats for different types of errors
@@ -45,6 +63,15 @@ const (
 	NegativeValue               = "Expect argument to be positive value. got: %d"
 	NegativeSecondValue         = "Expect second argument to be positive value. got: %d"
 	UndefinedMethod             = "Undefined Method '%+v' for %+v"
+	PrivateMethod               = "Private method '%s' called for %s"
+	ProtectedMethod             = "Protected method '%s' called for %s"
+	CantDefineSingletonMethod   = "Can't define singleton methods for %s"
+	KeyNotFound                 = "Key not found: %s"
+	CallStackTooDeep            = "Call stack too deep. maximum depth: %d"
+	WrongParameterType          = "Expect argument '%s' of method '%s' to be %s. got: %s"
+	WrongReturnType             = "Expect method '%s' to return %s. got: %s"
+	UndefinedLocal              = "Undefined local variable '%s'"
+	InvalidLocalName            = "Invalid local variable name '%s'"
 )
 
 // Classes a list of error classes to be initialised
@@ -59,4 +86,13 @@ var Classes = [...]string{
 	ZeroDivisionError,
 	ChannelCloseError,
 	NotImplementedError,
+	JSONError,
+	CSVError,
+	YAMLError,
+	TOMLError,
+	KeyError,
+	StopIteration,
+	TemplateError,
+	StackOverflowError,
+	SyntaxError,
 }
@@ -245,6 +245,22 @@ var fileInstanceMethods = []*BuiltinMethodObject{
 			return StringObject(result)
 		},
 	},
+	{
+		// Reads the rest of the file as Bytes
+		Name: "read_bytes",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+
+			data, err := io.ReadAll(receiver.(*FileObject).File)
+			if err != nil {
+				return t.vm.InitErrorObject(t, errors.IOError, err.Error())
+			}
+
+			return t.vm.InitBytesObject(data)
+		},
+	},
 	{
 		Name: "size",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
@@ -261,10 +277,17 @@ var fileInstanceMethods = []*BuiltinMethodObject{
 	{
 		Name: "write",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
-			// TODO: This needs more validation
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			data, ok := bytesValue(args[0])
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "String or Bytes", args[0].Class().Name)
+			}
+
 			file := receiver.(*FileObject).File
-			data := string(args[0].(StringObject))
-			length, err := file.Write([]byte(data))
+			length, err := file.Write(data)
 
 			if err != nil {
 				return t.vm.InitErrorObject(t, errors.IOError, err.Error())
@@ -28,7 +28,7 @@ var floatInstanceMethods = []*BuiltinMethodObject{
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
 			rightNumeric, ok := args[0].(Numeric)
 			if !ok {
-				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+				return coerceOperator(t, "+", receiver, args[0])
 			}
 			return FloatObject(float64(receiver.(FloatObject)) + rightNumeric.floatValue())
 		},
@@ -38,7 +38,7 @@ var floatInstanceMethods = []*BuiltinMethodObject{
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
 			rightNumeric, ok := args[0].(Numeric)
 			if !ok {
-				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+				return coerceOperator(t, "%", receiver, args[0])
 			}
 			if rightNumeric.floatValue() == 0 {
 				return t.vm.InitErrorObject(t, errors.ZeroDivisionError, errors.DividedByZero)
@@ -51,7 +51,7 @@ var floatInstanceMethods = []*BuiltinMethodObject{
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
 			rightNumeric, ok := args[0].(Numeric)
 			if !ok {
-				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+				return coerceOperator(t, "-", receiver, args[0])
 			}
 			return FloatObject(float64(receiver.(FloatObject)) - rightNumeric.floatValue())
 		},
@@ -61,7 +61,7 @@ var floatInstanceMethods = []*BuiltinMethodObject{
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
 			rightNumeric, ok := args[0].(Numeric)
 			if !ok {
-				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+				return coerceOperator(t, "*", receiver, args[0])
 			}
 			return FloatObject(float64(receiver.(FloatObject)) * rightNumeric.floatValue())
 		},
@@ -71,7 +71,7 @@ var floatInstanceMethods = []*BuiltinMethodObject{
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
 			rightNumeric, ok := args[0].(Numeric)
 			if !ok {
-				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+				return coerceOperator(t, "**", receiver, args[0])
 			}
 			return FloatObject(math.Pow(float64(receiver.(FloatObject)), rightNumeric.floatValue()))
 		},
@@ -81,7 +81,7 @@ var floatInstanceMethods = []*BuiltinMethodObject{
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
 			rightNumeric, ok := args[0].(Numeric)
 			if !ok {
-				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+				return coerceOperator(t, "/", receiver, args[0])
 			}
 
 			rightValue := rightNumeric.floatValue()
@@ -96,7 +96,7 @@ var floatInstanceMethods = []*BuiltinMethodObject{
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
 			rightObj, ok := args[0].(Numeric)
 			if !ok {
-				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+				return coerceOperator(t, ">", receiver, args[0])
 			}
 
 			return BooleanObject(float64(receiver.(FloatObject)) > rightObj.floatValue())
@@ -107,8 +107,7 @@ var floatInstanceMethods = []*BuiltinMethodObject{
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
 			rightObj, ok := args[0].(Numeric)
 			if !ok {
-				return t.vm.InitErrorObject(t, errors.TypeError,
-					errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+				return coerceOperator(t, ">=", receiver, args[0])
 			}
 			return BooleanObject(float64(receiver.(FloatObject)) >= rightObj.floatValue())
 		},
@@ -118,8 +117,7 @@ var floatInstanceMethods = []*BuiltinMethodObject{
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
 			rightObj, ok := args[0].(Numeric)
 			if !ok {
-				return t.vm.InitErrorObject(t, errors.TypeError,
-					errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+				return coerceOperator(t, "<", receiver, args[0])
 			}
 			return BooleanObject(float64(receiver.(FloatObject)) < rightObj.floatValue())
 		},
@@ -129,8 +127,7 @@ var floatInstanceMethods = []*BuiltinMethodObject{
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
 			rightObj, ok := args[0].(Numeric)
 			if !ok {
-				return t.vm.InitErrorObject(t, errors.TypeError,
-					errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+				return coerceOperator(t, "<=", receiver, args[0])
 			}
 			return BooleanObject(float64(receiver.(FloatObject)) <= rightObj.floatValue())
 		},
@@ -153,6 +150,47 @@ var floatInstanceMethods = []*BuiltinMethodObject{
 			return StringObject(r.ToJSON(t))
 		},
 	},
+	{
+		// Rounds to the nearest Integer, or to the given number of decimal places as a Float
+		Name: "round",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) > 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
+			}
+			f := float64(receiver.(FloatObject))
+			if len(args) == 0 {
+				return IntegerObject(int(math.Round(f)))
+			}
+			digits, ok := args[0].(IntegerObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.IntegerClass, args[0].Class().Name)
+			}
+			return FloatObject(roundFloat(f, int(digits)))
+		},
+		Primitive: true,
+	},
+	{
+		// Returns the float as a string, with the given number of decimal places if one is given
+		Name: "string",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) > 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
+			}
+			f := receiver.(FloatObject)
+			if len(args) == 0 {
+				return StringObject(f.ToString(t))
+			}
+			precision, ok := args[0].(IntegerObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.IntegerClass, args[0].Class().Name)
+			}
+			if precision < 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.NegativeValue, int(precision))
+			}
+			return StringObject(strconv.FormatFloat(float64(f), 'f', int(precision), 64))
+		},
+		Primitive: true,
+	},
 	{
 		Name: "inf?",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
@@ -185,6 +223,13 @@ func initFloatClass(vm *VM) *RClass {
 	return floatClass
 }
 
+// roundFloat rounds f to the given number of decimal places.
+// A negative number of places rounds to the left of the decimal point.
+func roundFloat(f float64, digits int) float64 {
+	p := math.Pow(10, float64(digits))
+	return math.Round(f*p) / p
+}
+
 // Value returns the object
 func (f FloatObject) Value() interface{} {
 	return float64(f)
@@ -198,6 +243,10 @@ func (f FloatObject) floatValue() float64 {
 // EqualTo apply an equality test, returning true if the objects are considered equal,
 // and false otherwise.
 func (f FloatObject) EqualTo(rightObject Object) bool {
+	switch rightObject.(type) {
+	case *DecimalObject, *RationalObject:
+		return rightObject.EqualTo(f)
+	}
 	rightNumeric, ok := rightObject.(Numeric)
 	return ok && float64(f) == rightNumeric.floatValue()
 }
@@ -0,0 +1,329 @@
+package vm
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+// GeneratorObject produces the values its block passes to a Yielder, one at a time.
+// The block runs in its own goroutine, which hands each value over and waits to be resumed,
+// so values are only computed as they are asked for.
+// A run left part way through by next or peek keeps its goroutine until the Generator is
+// closed or rewound, or is garbage collected. A Generator whose block refers to it, such as
+// through a local it is assigned to, can't be collected while the goroutine waits, so should
+// be closed when it is no longer needed.
+type GeneratorObject struct {
+	BaseObj
+	block  *BlockObject
+	run    *generatorRun
+	peeked Object
+}
+
+// YielderObject is passed to a Generator's block, to hand values to the Generator
+type YielderObject struct {
+	BaseObj
+	run *generatorRun
+}
+
+// generatorRun is a single run of a Generator's block
+type generatorRun struct {
+	resume  chan struct{}
+	values  chan Object
+	done    bool
+	stopped bool
+}
+
+var yielderClass *RClass
+
+// generatorStop is panicked with in a Generator's goroutine to unwind a run that has been stopped
+type generatorStop struct{}
+
+var generatorClassMethods = []*BuiltinMethodObject{
+	{
+		Name: "new",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			g := &GeneratorObject{
+				BaseObj: BaseObj{class: t.vm.TopLevelClass(classes.GeneratorClass)},
+				block:   blockFrame.blockObject(t.vm, blockFrame.self),
+			}
+			runtime.SetFinalizer(g, (*GeneratorObject).close)
+			return g
+		},
+	},
+}
+
+var generatorInstanceMethods = []*BuiltinMethodObject{
+	{
+		// Runs the block from the start, yielding each value. This does not affect next and peek.
+		Name: "each",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			g := receiver.(*GeneratorObject)
+			run := g.start(t)
+			defer run.stop()
+			for {
+				value, ok := run.next()
+				if !ok {
+					return g
+				}
+				if err, ok := value.(*Error); ok {
+					return err
+				}
+				t.Yield(blockFrame, value)
+				if blockFrame.IsRemoved() {
+					return g
+				}
+			}
+		},
+	},
+	{
+		// Returns the next value, raising StopIteration when there are no more
+		Name: "next",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+
+			g := receiver.(*GeneratorObject)
+			value := g.peek(t)
+			g.peeked = nil
+			return value
+		},
+	},
+	{
+		// Returns the next value without moving past it
+		Name: "peek",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return receiver.(*GeneratorObject).peek(t)
+		},
+	},
+	{
+		// Stops the current run, so that next starts again from the beginning
+		Name: "rewind",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+
+			g := receiver.(*GeneratorObject)
+			g.close()
+			return g
+		},
+	},
+	{
+		// Stops the current run, ending the goroutine running the block.
+		// As with rewind, next starts again from the beginning.
+		Name: "close",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			receiver.(*GeneratorObject).close()
+			return NIL
+		},
+	},
+}
+
+var yielderInstanceMethods = []*BuiltinMethodObject{
+	{
+		// Hands the value to the Generator, returning the Yielder
+		Name: "<<",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			receiver.(*YielderObject).run.yield(args[0])
+			return receiver
+		},
+	},
+	{
+		// Hands the value to the Generator. Several values are handed over as an Array.
+		Name: "yield",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			receiver.(*YielderObject).run.yield(enumValue(args))
+			return NIL
+		},
+	},
+}
+
+func initGeneratorClass(vm *VM) *RClass {
+	yielderClass = vm.InitClass(classes.YielderClass).
+		ClassMethods([]*BuiltinMethodObject{{Name: "new", Fn: NoSuchMethod("new"), Primitive: true}}).
+		InstanceMethods(yielderInstanceMethods)
+	generator := vm.InitClass(classes.GeneratorClass).
+		ClassMethods(generatorClassMethods).
+		InstanceMethods(generatorInstanceMethods).
+		include(enumerableModule)
+	generator.SetClassConstant(yielderClass)
+	return generator
+}
+
+// peek returns the next value, keeping it to be returned again
+func (g *GeneratorObject) peek(t *Thread) Object {
+	if g.peeked != nil {
+		return g.peeked
+	}
+	if g.run == nil {
+		g.run = g.start(t)
+	}
+
+	value, ok := g.run.next()
+	if !ok {
+		return t.vm.InitErrorObject(t, errors.StopIteration, "Iteration reached an end")
+	}
+	if _, isErr := value.(*Error); !isErr {
+		g.peeked = value
+	}
+	return value
+}
+
+// close stops the current run, if there is one
+func (g *GeneratorObject) close() {
+	if g.run != nil {
+		g.run.stop()
+	}
+	g.run = nil
+	g.peeked = nil
+}
+
+// start runs the block in a new goroutine, which waits for the first value to be asked for.
+// The goroutine doesn't refer to the Generator, so that it may be collected and the run stopped.
+func (g *GeneratorObject) start(t *Thread) *generatorRun {
+	run := &generatorRun{resume: make(chan struct{}), values: make(chan Object)}
+	block := g.block
+	yielder := &YielderObject{
+		BaseObj: BaseObj{class: yielderClass},
+		run:     run,
+	}
+
+	go func() {
+		defer close(run.values)
+		nt := t.vm.newThread()
+		defer func() {
+			switch e := recover().(type) {
+			case nil, generatorStop:
+			case *Error:
+				if !run.stopped {
+					run.values <- e
+				}
+			default:
+				if !run.stopped {
+					run.values <- nt.vm.InitErrorObject(nt, errors.InternalError, "%v", e)
+				}
+			}
+		}()
+
+		if _, ok := <-run.resume; !ok {
+			return
+		}
+		nt.Yield(block.asCallFrame(nt), yielder)
+	}()
+
+	return run
+}
+
+// next resumes the block and returns the value it hands over, or false if the block has finished
+func (r *generatorRun) next() (Object, bool) {
+	if r.done {
+		return nil, false
+	}
+	r.resume <- struct{}{}
+	value, ok := <-r.values
+	if !ok {
+		r.done = true
+		return nil, false
+	}
+	if _, isErr := value.(*Error); isErr {
+		r.done = true
+	}
+	return value, true
+}
+
+// yield hands the value over from the block's goroutine, and waits to be resumed
+func (r *generatorRun) yield(value Object) {
+	r.values <- value
+	if _, ok := <-r.resume; !ok {
+		panic(generatorStop{})
+	}
+}
+
+// stop ends the run, unwinding the block's goroutine if it is waiting to be resumed
+func (r *generatorRun) stop() {
+	if r.done {
+		return
+	}
+	r.done = true
+	r.stopped = true
+	close(r.resume)
+}
+
+// Value returns the object
+func (g *GeneratorObject) Value() interface{} {
+	return g.block
+}
+
+// ToString returns the object's name as the string format
+func (g *GeneratorObject) ToString(t *Thread) string {
+	return "#<Generator>"
+}
+
+// Inspect delegates to ToString
+func (g *GeneratorObject) Inspect(t *Thread) string {
+	return g.ToString(t)
+}
+
+// ToJSON just delegates to ToString
+func (g *GeneratorObject) ToJSON(t *Thread) string {
+	return g.ToString(t)
+}
+
+// EqualTo returns true if the objects are the same Generator
+func (g *GeneratorObject) EqualTo(with Object) bool {
+	return g == with
+}
+
+// Value returns the object
+func (y *YielderObject) Value() interface{} {
+	return y.run
+}
+
+// ToString returns the object's name as the string format
+func (y *YielderObject) ToString(t *Thread) string {
+	return fmt.Sprintf("#<%s>", classes.YielderClass)
+}
+
+// Inspect delegates to ToString
+func (y *YielderObject) Inspect(t *Thread) string {
+	return y.ToString(t)
+}
+
+// ToJSON just delegates to ToString
+func (y *YielderObject) ToJSON(t *Thread) string {
+	return y.ToString(t)
+}
+
+// EqualTo returns true if the objects are the same Yielder
+func (y *YielderObject) EqualTo(with Object) bool {
+	return y == with
+}
@@ -3,6 +3,7 @@ package vm
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/robotii/lito/vm/classes"
@@ -10,24 +11,39 @@ import (
 )
 
 // HashObject represents a map instance.
-// TODO: Think about a new representation for this
+// Keys are kept in the order they were first inserted, which is the order they are iterated in.
 type HashObject struct {
 	BaseObj
 	Pairs map[string]Object
+	// order holds the keys in insertion order, and index the position of each key in it.
+	// Removed keys are left in order until compact drops them.
+	order []string
+	index map[string]int
+	// Default is returned for a missing key, unless there is a defaultBlock to call instead
+	Default      Object
+	defaultBlock *BlockObject
 }
 
 var hashClass *RClass
 
 var hashClassMethods = []*BuiltinMethodObject{
 	{
+		// Creates an empty Hash. Missing keys return the given default value, or if a block is given,
+		// the result of calling it with the Hash and the key.
 		Name: "new",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
-			if len(args) != 0 {
-				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			if len(args) > 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
+			}
+			h := InitHashObject(make(map[string]Object))
+			if len(args) == 1 {
+				h.Default = args[0]
 			}
-			return InitHashObject(make(map[string]Object))
+			if blockFrame := t.GetBlock(); blockFrame != nil {
+				h.defaultBlock = blockFrame.blockObject(t.vm, blockFrame.self)
+			}
+			return h
 		},
-		Primitive: true,
 	},
 }
 
@@ -44,14 +60,7 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 					errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
 			}
 
-			h := receiver.(*HashObject)
-
-			value, ok := h.Pairs[string(key)]
-			if !ok {
-				return NIL
-			}
-
-			return value
+			return receiver.(*HashObject).get(t, string(key))
 		},
 	},
 	{
@@ -70,7 +79,7 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 			}
 
 			h := receiver.(*HashObject)
-			h.Pairs[string(key)] = args[1]
+			h.set(string(key), args[1])
 
 			return args[1]
 		},
@@ -91,9 +100,9 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 				return FALSE
 			}
 
-			for stringKey, value := range hash.Pairs {
+			for _, stringKey := range hash.keys() {
 				objectKey := StringObject(stringKey)
-				result := t.Yield(blockFrame, objectKey, value)
+				result := t.Yield(blockFrame, objectKey, hash.Pairs[stringKey])
 
 				if blockFrame.IsRemoved() {
 					return NIL
@@ -115,6 +124,8 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 			}
 			h := receiver.(*HashObject)
 			h.Pairs = make(map[string]Object)
+			h.order = nil
+			h.index = make(map[string]int)
 			return h
 		},
 	},
@@ -125,7 +136,7 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 			for _, d := range args {
 				deleteKey, ok := d.(StringObject)
 				if ok {
-					delete(hash.Pairs, string(deleteKey))
+					hash.remove(string(deleteKey))
 				} else {
 					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, d.Class().Name)
 				}
@@ -135,28 +146,76 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 				return hash
 			}
 
-			for stringKey, value := range hash.Pairs {
+			for _, stringKey := range hash.keys() {
 				objectKey := StringObject(stringKey)
-				result := t.Yield(blockFrame, objectKey, value)
+				result := t.Yield(blockFrame, objectKey, hash.Pairs[stringKey])
 
 				booleanResult, isResultBoolean := result.(BooleanObject)
 
 				if isResultBoolean {
 					if booleanResult {
-						delete(hash.Pairs, stringKey)
+						hash.remove(stringKey)
 					}
 				} else if result != NIL {
-					delete(hash.Pairs, stringKey)
+					hash.remove(stringKey)
 				}
 			}
 
 			return hash
 		},
 	},
+	{
+		Name: "default",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			if d := receiver.(*HashObject).Default; d != nil {
+				return d
+			}
+			return NIL
+		},
+	},
+	{
+		// Looks up each key in turn in nested Hashes and Arrays, returning nil if any is missing
+		Name: "dig",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) < 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentMore, 1, len(args))
+			}
+
+			var current Object = receiver
+			for _, key := range args {
+				switch c := current.(type) {
+				case *HashObject:
+					k, ok := key.(StringObject)
+					if !ok {
+						return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, key.Class().Name)
+					}
+					current = c.get(t, string(k))
+				case *ArrayObject:
+					i, ok := key.(IntegerObject)
+					if !ok {
+						return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.IntegerClass, key.Class().Name)
+					}
+					index := c.normaliseIndex(i)
+					if index < 0 || index >= len(c.Elements) {
+						return NIL
+					}
+					current = c.Elements[index]
+				default:
+					return NIL
+				}
+			}
+			return current
+		},
+	},
 	{
 		Name: "dup",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
-			return receiver.(*HashObject).copy()
+			newObj := receiver.(*HashObject).copy()
+			t.vm.dupSingletonClass(receiver, newObj)
+			return newObj
 		},
 	},
 	{
@@ -172,7 +231,7 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 
 			h := receiver.(*HashObject)
 
-			keys := h.sortedKeys()
+			keys := h.keys()
 
 			for _, k := range keys {
 				v := h.Pairs[k]
@@ -203,7 +262,7 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 
 			h := receiver.(*HashObject)
 
-			keys := h.sortedKeys()
+			keys := h.keys()
 			arrOfKeys := make([]Object, 0, len(keys))
 
 			for _, k := range keys {
@@ -232,7 +291,7 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 
 			h := receiver.(*HashObject)
 
-			keys := h.sortedKeys()
+			keys := h.keys()
 			arrOfValues := make([]Object, 0, len(keys))
 
 			for _, k := range keys {
@@ -248,6 +307,29 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 			return InitArrayObject(arrOfValues)
 		},
 	},
+	{
+		// Yields each key and value along with the index of the pair
+		Name: "each_with_index",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			h := receiver.(*HashObject)
+			for i, k := range h.keys() {
+				t.Yield(blockFrame, StringObject(k), h.Pairs[k], IntegerObject(i))
+				// If we break inside the block, then stop the iteration
+				if blockFrame.IsRemoved() {
+					break
+				}
+			}
+			return h
+		},
+	},
 	{
 		Name: "empty?",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
@@ -259,6 +341,83 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 			return BooleanObject(h.length() == 0)
 		},
 	},
+	{
+		// Returns the value of the key. If the key is missing, returns the result of the block
+		// called with the key, or the given default value, or raises a KeyError.
+		Name: "fetch",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) < 1 || len(args) > 2 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentRange, 1, 2, len(args))
+			}
+			key, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+			}
+
+			if value, ok := receiver.(*HashObject).Pairs[string(key)]; ok {
+				return value
+			}
+			if blockFrame := t.GetBlock(); blockFrame != nil {
+				return t.Yield(blockFrame, key)
+			}
+			if len(args) == 2 {
+				return args[1]
+			}
+			return t.vm.InitErrorObject(t, errors.KeyError, errors.KeyNotFound, string(key))
+		},
+	},
+	{
+		// Groups the pairs by the result of the block, returning a Hash of Arrays of [key, value] pairs
+		Name: "group_by",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			h := receiver.(*HashObject)
+			groups := newHashObject()
+			owners := map[string]Object{}
+			for _, k := range h.keys() {
+				group, err := uniqueHashKey(t, owners, t.Yield(blockFrame, StringObject(k), h.Pairs[k]))
+				if err != nil {
+					return err
+				}
+				pairs, ok := groups.Pairs[group].(*ArrayObject)
+				if !ok {
+					pairs = InitArrayObject(nil)
+					groups.set(group, pairs)
+				}
+				pairs.Elements = append(pairs.Elements, hashPair(k, h.Pairs[k]))
+			}
+			return groups
+		},
+	},
+	{
+		// Returns a Hash with the keys and values swapped. Values are converted to keys with hashKey,
+		// raising an ArgumentError if different values have the same key.
+		Name: "invert",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+
+			h := receiver.(*HashObject)
+			result := newHashObject()
+			owners := map[string]Object{}
+			for _, k := range h.keys() {
+				key, err := uniqueHashKey(t, owners, h.Pairs[k])
+				if err != nil {
+					return err
+				}
+				result.set(key, StringObject(k))
+			}
+			return result
+		},
+	},
 	{
 		Name: "key?",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
@@ -283,8 +442,8 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 			}
 
 			h := receiver.(*HashObject)
-			for _, v := range h.Pairs {
-				if v.EqualTo(args[0]) {
+			for _, k := range h.keys() {
+				if h.Pairs[k].EqualTo(args[0]) {
 					return TRUE
 				}
 			}
@@ -302,6 +461,14 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 			return IntegerObject(h.length())
 		},
 	},
+	{
+		Name: "max_by",
+		Fn:   hashExtremeBy(false),
+	},
+	{
+		Name: "min_by",
+		Fn:   hashExtremeBy(true),
+	},
 	{
 		Name: "map",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
@@ -317,7 +484,7 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 			resultArray := make([]Object, 0, len(h.Pairs))
 
 			if !blockFrame.IsEmpty() {
-				keys := h.sortedKeys()
+				keys := h.keys()
 				for _, k := range keys {
 					result := t.Yield(blockFrame, StringObject(k), h.Pairs[k])
 					resultArray = append(resultArray, result)
@@ -343,12 +510,11 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 				return h
 			}
 
-			resultHash := make(map[string]Object)
-			for k, v := range h.Pairs {
-				result := t.Yield(blockFrame, v)
-				resultHash[k] = result
+			resultHash := newHashObject()
+			for _, k := range h.keys() {
+				resultHash.set(k, t.Yield(blockFrame, h.Pairs[k]))
 			}
-			return InitHashObject(resultHash)
+			return resultHash
 		},
 	},
 	{
@@ -358,27 +524,36 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentMore, 1, len(args))
 			}
 
-			h := receiver.(*HashObject)
-			result := make(map[string]Object)
-			for k, v := range h.Pairs {
-				result[k] = v
-			}
+			result := receiver.(*HashObject).copy().(*HashObject)
 
 			for _, obj := range args {
 				hashObj, ok := obj.(*HashObject)
 				if !ok {
 					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.HashClass, obj.Class().Name)
 				}
-				for k, v := range hashObj.Pairs {
-					result[k] = v
+				for _, k := range hashObj.keys() {
+					result.set(k, hashObj.Pairs[k])
 				}
 			}
 
-			return InitHashObject(result)
+			return result
 		},
 	},
 	{
 		Name: "filter",
+		Fn:   hashFilter(true),
+	},
+	{
+		Name: "reject",
+		Fn:   hashFilter(false),
+	},
+	{
+		Name: "select",
+		Fn:   hashFilter(true),
+	},
+	{
+		// Returns an Array of [key, value] pairs ordered by the result of the block
+		Name: "sort_by",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
 			if len(args) != 0 {
 				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
@@ -388,23 +563,21 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
 			}
 
-			destinationPairs := map[string]Object{}
-			if blockFrame.IsEmpty() {
-				return InitHashObject(destinationPairs)
+			h := receiver.(*HashObject)
+			keys := h.keys()
+			results := make(map[string]Object, len(keys))
+			for _, k := range keys {
+				results[k] = t.Yield(blockFrame, StringObject(k), h.Pairs[k])
 			}
+			sort.SliceStable(keys, func(i, j int) bool {
+				return objectLessThan(results[keys[i]], results[keys[j]])
+			})
 
-			sourceHash := receiver.(*HashObject)
-
-			for stringKey, value := range sourceHash.Pairs {
-				objectKey := StringObject(stringKey)
-				result := t.Yield(blockFrame, objectKey, value)
-
-				if result.IsTruthy() {
-					destinationPairs[stringKey] = value
-				}
+			pairs := make([]Object, len(keys))
+			for i, k := range keys {
+				pairs[i] = hashPair(k, h.Pairs[k])
 			}
-
-			return InitHashObject(destinationPairs)
+			return InitArrayObject(pairs)
 		},
 	},
 	{
@@ -415,9 +588,8 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 			}
 
 			h := receiver.(*HashObject)
-			sortedKeys := h.sortedKeys()
-			var keys []Object
-			for _, k := range sortedKeys {
+			keys := make([]Object, 0, h.length())
+			for _, k := range h.keys() {
 				keys = append(keys, StringObject(k))
 			}
 			return InitArrayObject(keys)
@@ -425,22 +597,11 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 	},
 	{
 		Name: "array",
-		Fn: func(receiver Object, t *Thread, args []Object) Object {
-			aLen := len(args)
-			if aLen != 0 {
-				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, aLen)
-			}
-
-			h := receiver.(*HashObject)
-			var resultArr []Object
-			for _, k := range h.sortedKeys() {
-				var pairArr []Object
-				pairArr = append(pairArr, StringObject(k))
-				pairArr = append(pairArr, h.Pairs[k])
-				resultArr = append(resultArr, InitArrayObject(pairArr))
-			}
-			return InitArrayObject(resultArr)
-		},
+		Fn:   hashToArray,
+	},
+	{
+		Name: "to_a",
+		Fn:   hashToArray,
 	},
 	{
 		Name: "json",
@@ -491,31 +652,120 @@ var hashInstanceMethods = []*BuiltinMethodObject{
 			}
 
 			h := receiver.(*HashObject)
-			var keys []Object
-			sortedKeys := h.sortedKeys()
-			for _, v := range sortedKeys {
-				keys = append(keys, h.Pairs[v])
+			values := make([]Object, 0, h.length())
+			for _, k := range h.keys() {
+				values = append(values, h.Pairs[k])
 			}
-			return InitArrayObject(keys)
+			return InitArrayObject(values)
 		},
 	},
 }
 
-// InitHashObject initialise the HashObject
+// InitHashObject initialise the HashObject.
+// As a Go map has no order, the keys are ordered by sorting them.
+// To keep another order, fill the Hash from newHashObject with set.
 func InitHashObject(pairs map[string]Object) *HashObject {
+	order := make([]string, 0, len(pairs))
+	for k := range pairs {
+		order = append(order, k)
+	}
+	sort.Strings(order)
+
 	return &HashObject{
 		BaseObj: BaseObj{class: hashClass},
 		Pairs:   pairs,
+		order:   order,
+		index:   orderIndex(order),
 	}
 }
 
+// newHashObject returns an empty Hash, to be filled in order with set
+func newHashObject() *HashObject {
+	return InitHashObject(make(map[string]Object))
+}
+
 func initHashClass(vm *VM) *RClass {
 	hashClass = vm.InitClass(classes.HashClass).
 		ClassMethods(hashClassMethods).
-		InstanceMethods(hashInstanceMethods)
+		InstanceMethods(hashInstanceMethods).
+		include(enumerableModule)
 	return hashClass
 }
 
+// hashFilter returns a method selecting the pairs for which the block result is truthy,
+// or if keep is false, the pairs for which it is not
+func hashFilter(keep bool) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 0 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+		}
+		blockFrame := t.GetBlock()
+		if blockFrame == nil {
+			return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+		}
+
+		destination := newHashObject()
+		if blockFrame.IsEmpty() {
+			return destination
+		}
+
+		source := receiver.(*HashObject)
+		for _, k := range source.keys() {
+			value := source.Pairs[k]
+			if t.Yield(blockFrame, StringObject(k), value).IsTruthy() == keep {
+				destination.set(k, value)
+			}
+		}
+		return destination
+	}
+}
+
+// hashExtremeBy returns a method finding the [key, value] pair with the smallest block result,
+// or the largest if min is false. It returns nil for an empty Hash.
+func hashExtremeBy(min bool) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 0 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+		}
+		blockFrame := t.GetBlock()
+		if blockFrame == nil {
+			return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+		}
+
+		h := receiver.(*HashObject)
+		var found string
+		var best Object
+		for _, k := range h.keys() {
+			result := t.Yield(blockFrame, StringObject(k), h.Pairs[k])
+			if best == nil || (min && objectLessThan(result, best)) || (!min && objectLessThan(best, result)) {
+				found, best = k, result
+			}
+		}
+		if best == nil {
+			return NIL
+		}
+		return hashPair(found, h.Pairs[found])
+	}
+}
+
+func hashToArray(receiver Object, t *Thread, args []Object) Object {
+	if len(args) != 0 {
+		return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+	}
+
+	h := receiver.(*HashObject)
+	resultArr := make([]Object, 0, h.length())
+	for _, k := range h.keys() {
+		resultArr = append(resultArr, hashPair(k, h.Pairs[k]))
+	}
+	return InitArrayObject(resultArr)
+}
+
+// hashPair returns a key and value as an Array
+func hashPair(key string, value Object) *ArrayObject {
+	return InitArrayObject([]Object{StringObject(key), value})
+}
+
 // Value returns the object
 func (h *HashObject) Value() interface{} {
 	return h.Pairs
@@ -526,7 +776,7 @@ func (h *HashObject) ToString(t *Thread) string {
 	var out strings.Builder
 	var pairs []string
 
-	for _, key := range h.sortedKeys() {
+	for _, key := range h.keys() {
 		pairs = append(pairs, fmt.Sprintf("%s: %s", key, h.Pairs[key].ToString(t)))
 	}
 
@@ -542,7 +792,7 @@ func (h *HashObject) Inspect(t *Thread) string {
 	var out strings.Builder
 	var pairs []string
 
-	for _, key := range h.sortedKeys() {
+	for _, key := range h.keys() {
 		pairs = append(pairs, fmt.Sprintf("%s: %s", key, h.Pairs[key].Inspect(t)))
 	}
 
@@ -557,11 +807,10 @@ func (h *HashObject) Inspect(t *Thread) string {
 func (h *HashObject) ToJSON(t *Thread) string {
 	var out strings.Builder
 	var values []string
-	pairs := h.Pairs
 	out.WriteString("{")
 
-	for key, value := range pairs {
-		values = append(values, generateJSONFromPair(key, value, t))
+	for _, key := range h.keys() {
+		values = append(values, generateJSONFromPair(key, h.Pairs[key], t))
 	}
 
 	out.WriteString(strings.Join(values, ","))
@@ -569,32 +818,149 @@ func (h *HashObject) ToJSON(t *Thread) string {
 	return out.String()
 }
 
+// hashKey returns the key an object is stored under in a Hash.
+// Strings are their own keys, and objects without a string method are keyed by identity.
+func hashKey(t *Thread, obj Object) string {
+	switch o := obj.(type) {
+	case StringObject:
+		return string(o)
+	case *RObject:
+		if _, ok := o.FindMethod("string", false).(*MethodObject); !ok {
+			return fmt.Sprintf("#<%s:%p>", o.class.Name, o)
+		}
+	}
+	return obj.ToString(t)
+}
+
+// uniqueHashKey returns the key the object is stored under in a Hash being built, raising
+// an ArgumentError if a different object, such as 1 for "1", has already been given the key.
+// owners holds the object given each key so far.
+func uniqueHashKey(t *Thread, owners map[string]Object, obj Object) (string, *Error) {
+	key := hashKey(t, obj)
+	if owner, ok := owners[key]; ok && objectKey(t, owner) != objectKey(t, obj) {
+		return "", t.vm.InitErrorObject(t, errors.ArgumentError, "Can't use both %s and %s as the Hash key %s", owner.Inspect(t), obj.Inspect(t), key)
+	}
+	owners[key] = obj
+	return key, nil
+}
+
+// objectKey returns a key which is the same for objects that are the same, for a Set.
+// The class is part of the key, so 1 and "1" are different. Arrays and Hashes are keyed
+// by their contents, objects with a hash method by its result, objects with a string
+// method by their string form, and other objects by identity.
+func objectKey(t *Thread, obj Object) string {
+	switch o := obj.(type) {
+	case StringObject:
+		return classes.StringClass + ":" + strconv.Quote(string(o))
+	case *ArrayObject:
+		keys := make([]string, len(o.Elements))
+		for i, el := range o.Elements {
+			keys[i] = objectKey(t, el)
+		}
+		return classes.ArrayClass + ":[" + strings.Join(keys, ", ") + "]"
+	case *HashObject:
+		// Hashes with the same pairs are equal whatever their order
+		keys := make([]string, 0, len(o.Pairs))
+		for k, v := range o.Pairs {
+			keys = append(keys, strconv.Quote(k)+": "+objectKey(t, v))
+		}
+		sort.Strings(keys)
+		return classes.HashClass + ":{" + strings.Join(keys, ", ") + "}"
+	case *RObject:
+		if _, ok := o.FindMethod("hash", false).(*MethodObject); ok {
+			return fmt.Sprintf("%s#hash:%s", o.class.Name, t.CallMethod(o, "hash").ToString(t))
+		}
+		if _, ok := o.FindMethod("string", false).(*MethodObject); ok {
+			return o.class.Name + ":" + o.ToString(t)
+		}
+		return fmt.Sprintf("#<%s:%p>", o.class.Name, o)
+	}
+	return obj.Class().Name + ":" + obj.Inspect(t)
+}
+
 // Returns the length of the hash
 func (h *HashObject) length() int {
 	return len(h.Pairs)
 }
 
-// Returns the sorted keys of the hash
-func (h *HashObject) sortedKeys() []string {
-	var arr = make([]string, 0, len(h.Pairs))
-	for k := range h.Pairs {
-		arr = append(arr, k)
+// Returns a copy of the keys of the hash in insertion order, so they may be
+// iterated over while the hash is changed
+func (h *HashObject) keys() []string {
+	keys := make([]string, 0, len(h.index))
+	for i, k := range h.order {
+		if j, ok := h.index[k]; ok && j == i {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Sets the value of a key, adding the key to the end of the order if it is new
+func (h *HashObject) set(key string, value Object) {
+	if _, ok := h.Pairs[key]; !ok {
+		h.index[key] = len(h.order)
+		h.order = append(h.order, key)
+	}
+	h.Pairs[key] = value
+}
+
+// Removes a key and its value, leaving its place in the order to be dropped by compact
+func (h *HashObject) remove(key string) {
+	if _, ok := h.Pairs[key]; !ok {
+		return
+	}
+	delete(h.Pairs, key)
+	delete(h.index, key)
+	if len(h.order) > 2*len(h.index) {
+		h.compact()
+	}
+}
+
+// Drops the places of removed keys from the order
+func (h *HashObject) compact() {
+	h.order = h.keys()
+	h.index = orderIndex(h.order)
+}
+
+// Returns the position of each key in order
+func orderIndex(order []string) map[string]int {
+	index := make(map[string]int, len(order))
+	for i, k := range order {
+		index[k] = i
+	}
+	return index
+}
+
+// Returns the value of a key, or the default if the hash does not have the key
+func (h *HashObject) get(t *Thread, key string) Object {
+	if value, ok := h.Pairs[key]; ok {
+		return value
+	}
+	if h.defaultBlock != nil {
+		return t.Yield(h.defaultBlock.asCallFrame(t), h, StringObject(key))
+	}
+	if h.Default != nil {
+		return h.Default
 	}
-	sort.Strings(arr)
-	return arr
+	return NIL
 }
 
 // Returns the duplicate of the Hash object
 func (h *HashObject) copy() Object {
-	elems := map[string]Object{}
+	elems := make(map[string]Object, len(h.Pairs))
 
 	for k, v := range h.Pairs {
 		elems[k] = v
 	}
 
+	order := h.keys()
 	newHash := &HashObject{
-		BaseObj: BaseObj{class: h.class},
-		Pairs:   elems,
+		BaseObj:      BaseObj{class: h.class},
+		Pairs:        elems,
+		order:        order,
+		index:        orderIndex(order),
+		Default:      h.Default,
+		defaultBlock: h.defaultBlock,
 	}
 
 	return newHash
@@ -624,7 +990,7 @@ func (h *HashObject) EqualTo(with Object) bool {
 func generateJSONFromPair(key string, v Object, t *Thread) string {
 	var out strings.Builder
 
-	out.WriteString("\"" + key + "\"")
+	out.WriteString(strconv.Quote(key))
 	out.WriteString(":")
 	out.WriteString(v.ToJSON(t))
 
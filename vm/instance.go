@@ -7,13 +7,10 @@ type RObject struct {
 
 // ToString returns the object's name as the string format
 func (ro *RObject) ToString(t *Thread) string {
-	method := ro.FindMethod("string", false)
-	methodObj, ok := method.(*MethodObject)
-	if ok && t != nil {
-		t.Stack.Push(ro)
-		t.evalMethodCall(ro, methodObj, t.Stack.pointer, 0, nil, nil, t.GetSourceLine())
-		result := t.Stack.Pop()
-		return result.ToString(t)
+	if t != nil {
+		if result, ok := ro.callHook(t, "string"); ok {
+			return result.ToString(t)
+		}
 	}
 	return "#<" + ro.class.Name + ":instance >"
 }
@@ -29,20 +26,39 @@ func (ro *RObject) Inspect(t *Thread) string {
 	return "#<" + ro.class.Name + ":instance " + iv + ">"
 }
 
-// ToJSON just delegates to ToString
+// ToJSON uses the `to_json`, `as_json` or `json` methods if the class defines them,
+// otherwise it delegates to ToString.
+// `to_json` and `json` should return a JSON string, whereas `as_json` returns an
+// object which is then serialised itself, e.g. a Hash of the fields to be output.
 func (ro *RObject) ToJSON(t *Thread) string {
-	method := ro.FindMethod("json", false)
-	methodObj, ok := method.(*MethodObject)
-
-	if ok && t != nil {
-		t.Stack.Push(ro)
-		t.evalMethodCall(ro, methodObj, t.Stack.pointer, 0, nil, nil, t.GetSourceLine())
-		result := t.Stack.Pop()
+	if t == nil {
+		return ro.ToString(t)
+	}
+	if result, ok := ro.callHook(t, "to_json"); ok {
+		return result.ToString(t)
+	}
+	if result, ok := ro.callHook(t, "as_json"); ok {
+		return result.ToJSON(t)
+	}
+	if result, ok := ro.callHook(t, "json"); ok {
 		return result.ToString(t)
 	}
 	return ro.ToString(t)
 }
 
+// callHook calls a user defined method with no arguments, if it exists
+func (ro *RObject) callHook(t *Thread, name string) (Object, bool) {
+	return callHook(t, ro, name)
+}
+
+// callHook calls the named method if the receiver's class defines it in Lito
+func callHook(t *Thread, receiver Object, name string) (Object, bool) {
+	if _, ok := receiver.FindMethod(name, false).(*MethodObject); !ok {
+		return nil, false
+	}
+	return t.CallMethod(receiver, name), true
+}
+
 // Value returns object's string format
 func (ro *RObject) Value() interface{} {
 	return ro.ToString(nil)
@@ -12,17 +12,7 @@ func (t *Thread) execFrame(cf *CallFrame) {
 	// Defer handling - we use a pointer as we will allocate a new slice for
 	// deferStack, which will not be captured unless we use the pointer
 	defer func(deferStack *[]*CallFrame) {
-		// Execute defer statements in reverse order, like Go
-		for i := len(*deferStack) - 1; i >= 0; i-- {
-			blkFrame := (*deferStack)[i]
-			stackPtr := t.Stack.pointer
-			// Add something to the stack to prevent overwriting the return value
-			t.Stack.Push(NIL)
-			// Evaluate the frame
-			t.evaluateNormalFrame(blkFrame)
-			// Reset the stack pointer
-			t.Stack.pointer = stackPtr
-		}
+		t.runDefers(*deferStack)
 	}(&deferStack)
 
 	insCount := cf.instructionsCount()
@@ -48,28 +38,44 @@ func (t *Thread) execFrame(cf *CallFrame) {
 				opcode = bytecode.BinaryOperator
 				goto retry
 			}
-			// Discard the extra parameter
-			stack.Discard()
 			switch opcode {
 			case bytecode.Add:
-				stack.setTop(IntegerObject(int(l) + int(r)))
+				sum, ok := addInt(int(l), int(r))
+				if !ok {
+					// Let the method promote the result to a BigInteger
+					opcode = bytecode.BinaryOperator
+					goto retry
+				}
+				stack.Discard()
+				stack.setTop(IntegerObject(sum))
 
 			case bytecode.Subtract:
-				stack.setTop(IntegerObject(int(l) - int(r)))
+				diff, ok := subInt(int(l), int(r))
+				if !ok {
+					opcode = bytecode.BinaryOperator
+					goto retry
+				}
+				stack.Discard()
+				stack.setTop(IntegerObject(diff))
 
 			case bytecode.Less:
+				stack.Discard()
 				stack.setTop(BooleanObject(int(l) < int(r)))
 
 			case bytecode.Greater:
+				stack.Discard()
 				stack.setTop(BooleanObject(int(l) > int(r)))
 
 			case bytecode.LessEqual:
+				stack.Discard()
 				stack.setTop(BooleanObject(int(l) <= int(r)))
 
 			case bytecode.GreaterEqual:
+				stack.Discard()
 				stack.setTop(BooleanObject(int(l) >= int(r)))
 			}
-			cf.pc++
+			// Skip the method name and call site
+			cf.pc += 2
 
 		case bytecode.Pop:
 			stack.Discard()
@@ -94,37 +100,38 @@ func (t *Thread) execFrame(cf *CallFrame) {
 		case bytecode.GetConstant, bytecode.GetConstantNamespace:
 			constName := is.GetString(cf.pc)
 			cf.pc++
-			c := t.vm.lookupConstant(t, cf, constName)
-
-			if c == nil {
-				t.pushErrorObject(errors.NameError, "uninitialised constant '%s'", constName)
-				break
-			}
-
-			if stack.top() != nil && (stack.topFlags().has(namespace)) {
-				stack.Discard()
-			}
-
-			stack.Push(c.Target)
-			if opcode == bytecode.GetConstantNamespace {
-				stack.PushFlags(namespace)
-			}
+			t.getConstant(cf, constName, opcode == bytecode.GetConstantNamespace)
 
 		case bytecode.GetLocal:
 			depth := code[cf.pc]
 			cf.pc++
 			index := code[cf.pc]
 			cf.pc++
-			var obj Object
+			stack.Push(cf.localValue(index, depth))
 
-			p := cf.getLocal(index, depth)
-			if p == nil || p.Target == nil {
-				obj = NIL
-			} else {
-				obj = p.Target
+		case bytecode.GetLocalAddInt:
+			// Adds an Integer to a local, unless it needs the full add
+			obj := cf.localValue(code[cf.pc+1], code[cf.pc])
+			n := code[cf.pc+2]
+			if i, ok := obj.(IntegerObject); ok {
+				if sum, ok := addInt(int(i), n); ok {
+					stack.Push(IntegerObject(sum))
+					cf.pc += 5
+					break
+				}
 			}
-
 			stack.Push(obj)
+			stack.Push(IntegerObject(n))
+			cf.pc += 3
+			opcode = bytecode.Add
+			goto retry
+
+		case bytecode.GetLocalSend:
+			// Calls a method on a local
+			stack.Push(cf.localValue(code[cf.pc+1], code[cf.pc]))
+			cf.pc += 2
+			opcode = bytecode.Send
+			goto retry
 
 		case bytecode.GetInstanceVariable:
 			variableName := is.GetString(cf.pc)
@@ -150,9 +157,8 @@ func (t *Thread) execFrame(cf *CallFrame) {
 			index := code[cf.pc]
 			cf.pc++
 
-			ptr := cf.getLocal(index, depth)
 			// We may preallocate these for efficiency
-			if ptr == nil || ptr.Target == nil {
+			if cf.getLocal(index, depth) == nil {
 				cf.insertLocal(index, depth, p)
 			}
 
@@ -168,67 +174,20 @@ func (t *Thread) execFrame(cf *CallFrame) {
 		case bytecode.SetConstant:
 			constName := is.GetString(cf.pc)
 			cf.pc++
-			c := cf.lookupConstantInCurrentScope(constName)
-			v := stack.Pop()
-
-			if c != nil {
-				t.pushErrorObject(errors.ConstantAlreadyInitialisedError, "Constant %s already initialised. Can't assign value to a constant twice.", constName)
-			}
-
-			cf.storeConstant(constName, v)
+			t.setConstant(cf, constName)
 
 		case bytecode.NewRange, bytecode.NewRangeExcl:
-			re := stack.Pop()
-			rs := stack.Pop()
-			rangeEnd, ok1 := re.(IntegerObject)
-			if !ok1 {
-				t.pushErrorObject(errors.ArgumentError, errors.WrongArgumentTypeFormat, classes.IntegerClass, re.Class().Name)
-			}
-			rangeStart, ok2 := rs.(IntegerObject)
-			if !ok2 {
-				t.pushErrorObject(errors.ArgumentError, errors.WrongArgumentTypeFormat, classes.IntegerClass, rs.Class().Name)
-			}
-			stack.Push(initRangeObject(t.vm, int(rangeStart), int(rangeEnd), opcode == bytecode.NewRangeExcl))
+			t.newRange(opcode == bytecode.NewRangeExcl)
 
 		case bytecode.NewArray:
 			argCount := code[cf.pc]
 			cf.pc++
-
-			var elems = make([]Object, argCount)
-
-			for i := argCount - 1; i >= 0; i-- {
-				v := stack.Pop()
-				elems[i] = v
-			}
-
-			arr := InitArrayObject(elems)
-			stack.Push(arr)
+			t.newArray(argCount)
 
 		case bytecode.ExpandArray:
 			arrLength := code[cf.pc]
 			cf.pc++
-			arr, ok := stack.Pop().(*ArrayObject)
-
-			if !ok {
-				t.pushErrorObject(errors.TypeError, "Expect stack top's value to be an Array when executing 'expandarray' instruction.")
-			}
-
-			var elems []Object
-
-			for i := 0; i < arrLength; i++ {
-				var elem Object
-				if i < len(arr.Elements) {
-					elem = arr.Elements[i]
-				} else {
-					elem = NIL
-				}
-
-				elems = append([]Object{elem}, elems...)
-			}
-
-			for _, elem := range elems {
-				stack.Push(elem)
-			}
+			t.expandArray(arrLength)
 
 		case bytecode.SplatArray:
 			obj := stack.top()
@@ -247,14 +206,7 @@ func (t *Thread) execFrame(cf *CallFrame) {
 		case bytecode.NewHash:
 			argCount := code[cf.pc]
 			cf.pc++
-			pairs := map[string]Object{}
-
-			for i := 0; i < argCount/2; i++ {
-				v := stack.Pop()
-				k := stack.Pop()
-				pairs[string(k.(StringObject))] = v
-			}
-			stack.Push(InitHashObject(pairs))
+			t.newHash(argCount)
 
 		case bytecode.BranchUnless:
 			v := stack.Pop()
@@ -296,11 +248,7 @@ func (t *Thread) execFrame(cf *CallFrame) {
 			cf.pc = code[cf.pc]
 
 		case bytecode.Break:
-			if cf.IsBlock() {
-				frame := t.callFrameStack.pop()
-				frame.stopExecution()
-				frame.setAsRemoved()
-			}
+			t.breakBlock(cf)
 
 		case bytecode.PutSelf:
 			stack.Push(cf.self)
@@ -317,259 +265,457 @@ func (t *Thread) execFrame(cf *CallFrame) {
 			stack.Push(FloatObject(is.GetFloat(cf.pc)))
 			cf.pc++
 
+		case bytecode.PutRegexp:
+			pattern := is.GetString(cf.pc)
+			cf.pc++
+			t.putRegexp(pattern)
+
 		case bytecode.PutNull:
 			stack.Push(NIL)
 
-		case bytecode.DefMethod:
+		case bytecode.DefMethod, bytecode.DefMetaMethod:
 			argCount := code[cf.pc]
-			cf.pc++
-			methodName := is.GetString(cf.pc)
-			cf.pc++
-			is, ok := is.GetObject(cf.pc).(*bytecode.InstructionSet)
-			cf.pc++
-			if !ok {
-				t.pushErrorObject(errors.InternalError, "Can't get method %s's instruction set.", methodName)
-			}
+			methodName := is.GetString(cf.pc + 1)
+			methodIS, _ := is.GetObject(cf.pc + 2).(*bytecode.InstructionSet)
+			cf.pc += 3
+			t.defMethod(methodName, argCount, methodIS, opcode == bytecode.DefMetaMethod)
 
-			method := &MethodObject{Name: methodName, argc: argCount, instructionSet: is, BaseObj: BaseObj{class: t.vm.TopLevelClass(classes.MethodClass)}}
+		case bytecode.DefClass:
+			subjectType, subjectName := is.GetString(cf.pc), is.GetString(cf.pc+1)
+			classIS := is.GetObject(cf.pc + 2).(*bytecode.InstructionSet)
+			superClassName := is.GetString(cf.pc + 3)
+			cf.pc += 4
+			t.defClass(cf, subjectType, subjectName, classIS, superClassName)
 
-			v := stack.Pop()
-			switch self := v.(type) {
-			case *RClass:
-				self.Methods[methodName] = method
-			default:
-				self.Class().Methods[methodName] = method
+		case bytecode.Send:
+			methodName := is.GetString(cf.pc)
+			argCount := code[cf.pc+1]
+			blockIS, _ := is.GetObject(cf.pc + 2).(*bytecode.InstructionSet)
+			// We don't want a panic here, nil is fine for argset
+			argSet, _ := is.GetObject(cf.pc + 3).(*bytecode.ArgSet)
+			site, _ := is.GetObject(cf.pc + 4).(*bytecode.CallSite)
+			cf.pc += 5
+			t.send(cf, methodName, argCount, blockIS, argSet, site)
+
+		case bytecode.TailSend:
+			methodName := is.GetString(cf.pc)
+			argCount := code[cf.pc+1]
+			blockIS, _ := is.GetObject(cf.pc + 2).(*bytecode.InstructionSet)
+			argSet, _ := is.GetObject(cf.pc + 3).(*bytecode.ArgSet)
+			site, _ := is.GetObject(cf.pc + 4).(*bytecode.CallSite)
+			cf.pc += 5
+			if !t.tailCall(cf, methodName, argCount, argSet, site) {
+				t.send(cf, methodName, argCount, blockIS, argSet, site)
+				break
 			}
-			// DEBUG: Uncomment this line to write out the method definition
-			//os.Stderr.Write([]byte(method.Inspect(t) + "\n"))
+			// Carry on in the same frame with the method called
+			is = cf.instructionSet
+			code = is.Instructions
+			insCount = cf.instructionsCount()
 
-		case bytecode.DefMetaMethod:
-			argCount := code[cf.pc]
-			cf.pc++
+		case bytecode.BinaryOperator:
 			methodName := is.GetString(cf.pc)
+			site, _ := is.GetObject(cf.pc + 1).(*bytecode.CallSite)
+			cf.pc += 2
+			t.binaryOperator(cf, methodName, site)
+
+		case bytecode.InvokeBlock:
+			argCount := code[cf.pc]
 			cf.pc++
-			is, ok := is.GetObject(cf.pc).(*bytecode.InstructionSet)
-			cf.pc++
-			if !ok {
-				t.pushErrorObject(errors.InternalError, "Can't get method %s's instruction set.", methodName)
-			}
-			method := &MethodObject{Name: methodName, argc: argCount, instructionSet: is, BaseObj: BaseObj{class: t.vm.TopLevelClass(classes.MethodClass)}}
+			t.invokeBlock(cf, argCount)
 
-			v := stack.Pop()
-			switch v := v.(type) {
-			case *RClass:
-				if metaClass := v.MetaClass(); metaClass != nil {
-					metaClass.Methods[methodName] = method
-				}
-			default:
-				// TODO: Should we return an error here?
-			}
-			// DEBUG: Uncomment this line to write out the method definition
-			//os.Stderr.Write([]byte(method.Inspect(t) + "\n"))
+		case bytecode.GetBlock:
+			t.getBlock(cf)
 
-		case bytecode.DefClass:
-			subjectType, subjectName := is.GetString(cf.pc), is.GetString(cf.pc+1)
+		case bytecode.HasBlock:
+			stack.Push(BooleanObject(cf.blockFrame != nil))
+
+		case bytecode.Leave:
+			cf.stopExecution()
+
+		case bytecode.Defer:
+			argCount := code[cf.pc]
+			blockIS, _ := is.GetObject(cf.pc + 1).(*bytecode.InstructionSet)
 			cf.pc += 2
-			classPtr := cf.lookupConstantUnderAllScope(subjectName)
+			if c := t.deferBlock(cf, argCount, blockIS); c != nil {
+				deferStack = append(deferStack, c)
+			}
 
-			classIS := is.GetObject(cf.pc).(*bytecode.InstructionSet)
-			cf.pc++
+		case bytecode.NoOp:
 
-			if classPtr == nil {
-				var class *RClass
-				if subjectType == "module" {
-					class = t.vm.InitModule(subjectName)
-				} else {
-					class = t.vm.InitClass(subjectName)
-				}
+		default:
+			panic("Unexpected bytecode")
+		}
+	}
+}
 
-				classPtr = cf.storeConstant(class.Name, class)
-				superClassName := is.GetString(cf.pc)
+// runDefers evaluates the deferred block frames in reverse order, like Go
+func (t *Thread) runDefers(deferStack []*CallFrame) {
+	for i := len(deferStack) - 1; i >= 0; i-- {
+		blkFrame := deferStack[i]
+		stackPtr := t.Stack.pointer
+		// Add something to the stack to prevent overwriting the return value
+		t.Stack.Push(NIL)
+		// Evaluate the frame
+		t.evaluateNormalFrame(blkFrame)
+		// Reset the stack pointer
+		t.Stack.pointer = stackPtr
+	}
+}
 
-				if superClassName != bytecode.NoSuperClass {
-					if superClassName == "" {
-						t.pushErrorObject(errors.InternalError, "Invalid constant for superclass")
-					}
-					superClass := t.vm.lookupConstant(t, cf, superClassName)
-					inheritedClass, ok := superClass.Target.(*RClass)
-					if !ok {
-						t.pushErrorObject(errors.InternalError, "Constant %s is not a class. got: %s", superClassName, superClass.Target.Class().Name)
-					}
+func (t *Thread) getConstant(cf *CallFrame, constName string, isNamespace bool) {
+	stack := &t.Stack
+	c := t.vm.lookupConstant(t, cf, constName)
 
-					if inheritedClass.isModule {
-						t.pushErrorObject(errors.InternalError, "Module inheritance is not supported: %s", inheritedClass.Name)
-					}
+	if c == nil {
+		t.pushErrorObject(errors.NameError, "uninitialised constant '%s'", constName)
+		return
+	}
 
-					class.inherits(inheritedClass)
-				}
-			}
-			cf.pc++
+	if stack.top() != nil && (stack.topFlags().has(namespace)) {
+		stack.Discard()
+	}
 
-			stack.Discard()
-			c := newNormalCallFrame(classIS, cf.FileName(), t.GetSourceLine())
-			c.self = classPtr.Target
-			t.evaluateNormalFrame(c)
-			stack.Push(classPtr.Target)
+	stack.Push(c.Target)
+	if isNamespace {
+		stack.PushFlags(namespace)
+	}
+}
 
-		case bytecode.Send:
-			methodName := is.GetString(cf.pc)
-			cf.pc++
-			argCount := code[cf.pc]
-			cf.pc++
-			blockIS, ok := is.GetObject(cf.pc).(*bytecode.InstructionSet)
-			cf.pc++
-			if !ok {
-				blockIS = nil
-			}
+func (t *Thread) setConstant(cf *CallFrame, constName string) {
+	c := cf.lookupConstantInCurrentScope(constName)
+	v := t.Stack.Pop()
 
-			// We don't want a panic here, nil is fine for argset
-			argSet, _ := is.GetObject(cf.pc).(*bytecode.ArgSet)
-			cf.pc++
+	if c != nil {
+		t.pushErrorObject(errors.ConstantAlreadyInitialisedError, "Constant %s already initialised. Can't assign value to a constant twice.", constName)
+	}
 
-			// Handle splatted block as last argument
-			// Check if we have an argument, as we don't want to splat the receiver
-			argCount, blockFrame := t.unsplatBlock(cf, argCount, blockIS)
+	// Name an anonymous class after the constant it is first assigned to
+	if class, ok := v.(*RClass); ok && class.Name == "" {
+		class.Name = constName
+		class.metaClass.Name = "#<Class:" + constName + ">"
+	}
+	cf.storeConstant(constName, v)
+}
 
-			// Set up the blockframe for execution
-			if blockFrame != nil {
-				blockFrame.ep = cf
-				blockFrame.self = cf.self
-				blockFrame.sourceLine = t.GetSourceLine()
-			}
+func (t *Thread) newRange(exclusive bool) {
+	re := t.Stack.Pop()
+	rs := t.Stack.Pop()
+	rangeEnd, ok1 := re.(IntegerObject)
+	if !ok1 {
+		t.pushErrorObject(errors.ArgumentError, errors.WrongArgumentTypeFormat, classes.IntegerClass, re.Class().Name)
+	}
+	rangeStart, ok2 := rs.(IntegerObject)
+	if !ok2 {
+		t.pushErrorObject(errors.ArgumentError, errors.WrongArgumentTypeFormat, classes.IntegerClass, rs.Class().Name)
+	}
+	t.Stack.Push(initRangeObject(t.vm, int(rangeStart), int(rangeEnd), exclusive))
+}
+
+func (t *Thread) newArray(argCount int) {
+	var elems = make([]Object, argCount)
 
-			// Deal with splat arguments
-			argCount = t.unsplatArray(argCount)
+	for i := argCount - 1; i >= 0; i-- {
+		v := t.Stack.Pop()
+		elems[i] = v
+	}
 
-			argPr := stack.pointer - argCount
-			receiverPr := argPr - 1
-			receiver := stack.data[receiverPr]
+	arr := InitArrayObject(elems)
+	t.Stack.Push(arr)
+}
 
-			// Find Method
-			super := stack.flags[receiverPr].has(superRef)
-			t.FindAndExecute(receiver, methodName, super, receiverPr, argPr, argCount, argSet, blockFrame, cf.fileName)
+func (t *Thread) expandArray(arrLength int) {
+	value := t.Stack.Pop()
+	// Objects can be destructured by returning an Array from deconstruct
+	if _, isArray := value.(*ArrayObject); !isArray && value.FindMethod("deconstruct", false) != nil {
+		value = t.CallMethod(value, "deconstruct")
+	}
+	arr, ok := value.(*ArrayObject)
 
-		case bytecode.BinaryOperator:
-			methodName := is.GetString(cf.pc)
-			cf.pc++
-			argCount := 1
+	if !ok {
+		t.pushErrorObject(errors.TypeError, "Expect stack top's value to be an Array when executing 'expandarray' instruction.")
+	}
 
-			argPr := stack.pointer - argCount
-			receiverPr := argPr - 1
-			receiver := stack.data[receiverPr]
+	var elems []Object
 
-			// Find Method
-			super := stack.flags[receiverPr].has(superRef)
-			t.FindAndExecute(receiver, methodName, super, receiverPr, argPr, argCount, nil, nil, cf.fileName)
+	for i := 0; i < arrLength; i++ {
+		var elem Object
+		if i < len(arr.Elements) {
+			elem = arr.Elements[i]
+		} else {
+			elem = NIL
+		}
 
-		case bytecode.InvokeBlock:
-			argCount := code[cf.pc]
-			cf.pc++
-			argPr := stack.pointer - argCount
-			receiverPr := argPr - 1
-			receiver := stack.data[receiverPr]
+		elems = append([]Object{elem}, elems...)
+	}
 
-			if cf.blockFrame == nil {
-				t.pushErrorObject(errors.InternalError, errors.CantYieldWithoutBlockFormat)
-			}
+	for _, elem := range elems {
+		t.Stack.Push(elem)
+	}
+}
 
-			blockFrame := cf.blockFrame
+func (t *Thread) newHash(argCount int) {
+	items := make([]Object, argCount)
+	for i := argCount - 1; i >= 0; i-- {
+		items[i] = t.Stack.Pop()
+	}
 
-			if cf.blockFrame.ep == cf.ep {
-				blockFrame = cf.ep.blockFrame
-			}
+	// Set the pairs in source order, so the Hash keeps their insertion order
+	h := newHashObject()
+	for i := 0; i+1 < argCount; i += 2 {
+		h.set(string(items[i].(StringObject)), items[i+1])
+	}
+	t.Stack.Push(h)
+}
 
-			// Check we have a valid block frame still
-			if blockFrame == nil {
-				t.pushErrorObject(errors.InternalError, errors.CantYieldWithoutBlockFormat)
-			}
+func (t *Thread) breakBlock(cf *CallFrame) {
+	if cf.IsBlock() {
+		frame := t.callFrameStack.pop()
+		frame.stopExecution()
+		frame.setAsRemoved()
+	}
+}
 
-			c := newNormalCallFrame(blockFrame.instructionSet, blockFrame.instructionSet.Filename, blockFrame.instructionSet.SourceMap[0])
-			c.blockFrame = blockFrame
-			c.ep = blockFrame.ep
-			c.self = receiver
-			c.isBlock = true
-			c.initLocalsFrom(stack.data[argPr : argPr+argCount]...)
+func (t *Thread) putRegexp(pattern string) {
+	r := initRegexpObject(t.vm, pattern)
+	if r == nil {
+		t.pushErrorObject(errors.ArgumentError, "Invalid regexp: %v", pattern)
+	}
+	t.Stack.Push(r)
+}
 
-			t.evaluateNormalFrame(c)
+// defMethod defines the method on the class on top of the stack, or on its
+// metaclass for class methods
+func (t *Thread) defMethod(methodName string, argCount int, is *bytecode.InstructionSet, meta bool) {
+	if is == nil {
+		t.pushErrorObject(errors.InternalError, "Can't get method %s's instruction set.", methodName)
+	}
 
-			stack.Set(receiverPr, stack.top())
-			stack.pointer = receiverPr + 1
+	method := &MethodObject{Name: methodName, argc: argCount, instructionSet: is, BaseObj: BaseObj{class: t.vm.TopLevelClass(classes.MethodClass)}}
 
-		case bytecode.GetBlock:
-			blockFrame := cf.blockFrame
-			if blockFrame == nil {
-				t.pushErrorObject(errors.InternalError, "Can't get block without a block argument")
-			}
+	v := t.Stack.Pop()
+	switch self := v.(type) {
+	case *RClass:
+		if !meta {
+			self.setMethod(methodName, method)
+			t.callHook(self, "method_added", StringObject(methodName))
+		} else if metaClass := self.MetaClass(); metaClass != nil {
+			metaClass.setMethod(methodName, method)
+		}
+	default:
+		if !meta {
+			self.Class().setMethod(methodName, method)
+			t.callHook(self.Class(), "method_added", StringObject(methodName))
+			break
+		}
+		singleton, err := t.singletonClassOf(self)
+		if err != nil {
+			t.pushErrorObject(errors.TypeError, errors.CantDefineSingletonMethod, self.Class().Name)
+		}
+		singleton.setMethod(methodName, method)
+	}
+	// DEBUG: Uncomment this line to write out the method definition
+	//os.Stderr.Write([]byte(method.Inspect(t) + "\n"))
+}
+
+func (t *Thread) defClass(cf *CallFrame, subjectType, subjectName string, classIS *bytecode.InstructionSet, superClassName string) {
+	classPtr := cf.lookupConstantUnderAllScope(subjectName)
+
+	if classPtr == nil {
+		var class *RClass
+		if subjectType == "module" {
+			class = t.vm.InitModule(subjectName)
+		} else {
+			class = t.vm.InitClass(subjectName)
+		}
 
-			// If the blockframe is from the level up, then reuse that
-			if cf.blockFrame.ep == cf.ep && cf.ep != nil && cf.blockFrame.ep.blockFrame != nil {
-				blockFrame = cf.ep.blockFrame
+		classPtr = cf.storeConstant(class.Name, class)
+
+		if superClassName != bytecode.NoSuperClass {
+			if superClassName == "" {
+				t.pushErrorObject(errors.InternalError, "Invalid constant for superclass")
+			}
+			superClass := t.vm.lookupConstant(t, cf, superClassName)
+			inheritedClass, ok := superClass.Target.(*RClass)
+			if !ok {
+				t.pushErrorObject(errors.InternalError, "Constant %s is not a class. got: %s", superClassName, superClass.Target.Class().Name)
 			}
 
-			// Check again, just to make sure
-			if blockFrame == nil {
-				t.pushErrorObject(errors.InternalError, "Can't get block without a block argument")
+			if inheritedClass.isModule {
+				t.pushErrorObject(errors.InternalError, "Module inheritance is not supported: %s", inheritedClass.Name)
 			}
 
-			blockObject := initBlockObject(t.vm, blockFrame.instructionSet, blockFrame.ep, stack.data[stack.pointer-1])
-			stack.Push(blockObject)
+			class.inherits(inheritedClass)
+			t.callHook(inheritedClass, "inherited", class)
+		}
+	}
 
-		case bytecode.HasBlock:
-			stack.Push(BooleanObject(cf.blockFrame != nil))
+	t.Stack.Discard()
+	c := newNormalCallFrame(classIS, cf.FileName(), t.GetSourceLine())
+	c.self = classPtr.Target
+	t.evaluateNormalFrame(c)
+	t.Stack.Push(classPtr.Target)
+}
 
-		case bytecode.Leave:
-			cf.stopExecution()
+func (t *Thread) send(cf *CallFrame, methodName string, argCount int, blockIS *bytecode.InstructionSet, argSet *bytecode.ArgSet, site *bytecode.CallSite) {
+	stack := &t.Stack
 
-		case bytecode.Defer:
-			argCount := code[cf.pc]
-			cf.pc++
-			blockIS, ok := is.GetObject(cf.pc).(*bytecode.InstructionSet)
-			cf.pc++
-			if !ok {
-				blockIS = nil
-			}
+	// Handle splatted block as last argument
+	// Check if we have an argument, as we don't want to splat the receiver
+	argCount, blockFrame := t.unsplatBlock(cf, argCount, blockIS)
 
-			// Allow passing a block as argument
-			// Check if we have an argument, as we don't want to splat the receiver
-			argCount, blockFrame := t.unsplatBlock(cf, argCount, blockIS)
+	// Set up the blockframe for execution
+	if blockFrame != nil {
+		blockFrame.ep = cf
+		blockFrame.self = cf.self
+		blockFrame.sourceLine = t.GetSourceLine()
+	}
 
-			// Deal with splat arguments
-			argCount = t.unsplatArray(argCount)
+	// Deal with splat arguments
+	argCount = t.unsplatArray(argCount)
 
-			argPr := stack.pointer - argCount
-			receiverPr := argPr - 1
+	argPr := stack.pointer - argCount
+	receiverPr := argPr - 1
+	receiver := stack.data[receiverPr]
 
-			// Set up the blockframe for execution
-			if blockFrame != nil {
-				blockFrame.ep = cf
-				// We take a copy of the receiver as it is at the time
-				blockFrame.self = stack.data[receiverPr]
-				blockFrame.sourceLine = t.GetSourceLine()
+	// Find Method
+	super := stack.flags[receiverPr].has(superRef)
+	t.FindAndExecute(receiver, methodName, super, receiverPr, argPr, argCount, argSet, blockFrame, cf.fileName, site)
+}
 
-				c := newNormalCallFrame(blockFrame.instructionSet, blockFrame.instructionSet.Filename, t.GetSourceLine())
-				c.blockFrame = blockFrame
-				c.ep = blockFrame.ep
-				c.self = stack.data[receiverPr]
-				c.isBlock = true
+func (t *Thread) binaryOperator(cf *CallFrame, methodName string, site *bytecode.CallSite) {
+	stack := &t.Stack
+	argCount := 1
 
-				// Populate any arguments at the time of the defer call
-				// This matches the Go semantics
-				c.initLocalsFrom(stack.data[argPr : argPr+argCount]...)
+	argPr := stack.pointer - argCount
+	receiverPr := argPr - 1
+	receiver := stack.data[receiverPr]
 
-				// Append the call frame to the stack
-				deferStack = append(deferStack, c)
-			}
+	// Find Method
+	super := stack.flags[receiverPr].has(superRef)
+	t.FindAndExecute(receiver, methodName, super, receiverPr, argPr, argCount, nil, nil, cf.fileName, site)
+}
 
-			stack.pointer = receiverPr + 1
-			// Push something onto the stack for the next instruction
-			stack.Push(NIL)
+func (t *Thread) invokeBlock(cf *CallFrame, argCount int) {
+	stack := &t.Stack
+	argPr := stack.pointer - argCount
+	receiverPr := argPr - 1
+	receiver := stack.data[receiverPr]
 
-		case bytecode.NoOp:
+	if cf.blockFrame == nil {
+		t.pushErrorObject(errors.InternalError, errors.CantYieldWithoutBlockFormat)
+	}
 
-		default:
-			panic("Unexpected bytecode")
-		}
+	blockFrame := cf.blockFrame
+
+	if cf.blockFrame.ep == cf.ep && cf.ep != nil {
+		blockFrame = cf.ep.blockFrame
+	}
+
+	// Check we have a valid block frame still
+	if blockFrame == nil {
+		t.pushErrorObject(errors.InternalError, errors.CantYieldWithoutBlockFormat)
+	}
+
+	if blockFrame.native != nil {
+		args := make([]Object, argCount)
+		copy(args, stack.data[argPr:argPr+argCount])
+		stack.Set(receiverPr, blockFrame.native(args))
+		stack.pointer = receiverPr + 1
+		t.stopIfBlockRemoved(cf, blockFrame)
+		return
 	}
+
+	c := t.newFrame(blockFrame.instructionSet, blockFrame.instructionSet.Filename, blockFrame.instructionSet.SourceMap[0])
+	c.blockFrame = blockFrame
+	c.ep = blockFrame.ep
+	c.self = receiver
+	c.isBlock = true
+	c.initLocalsFrom(stack.data[argPr : argPr+argCount]...)
+
+	t.evaluateNormalFrame(c)
+	t.releaseFrame(c)
+
+	stack.Set(receiverPr, stack.top())
+	stack.pointer = receiverPr + 1
+	t.stopIfBlockRemoved(cf, blockFrame)
+}
+
+// stopIfBlockRemoved ends the frame which yielded to the block if the block was broken out of,
+// or told to stop by the method enumerating it, as builtin methods stop yielding.
+// A yielding block is broken out of in turn, so that the method it was given to stops too.
+func (t *Thread) stopIfBlockRemoved(cf *CallFrame, blockFrame *CallFrame) {
+	if !blockFrame.IsRemoved() {
+		return
+	}
+	t.Stack.Set(t.Stack.pointer-1, NIL)
+	if cf.IsBlock() {
+		t.breakBlock(cf)
+		return
+	}
+	cf.stopExecution()
+}
+
+func (t *Thread) getBlock(cf *CallFrame) {
+	stack := &t.Stack
+	blockFrame := cf.blockFrame
+	if blockFrame == nil {
+		t.pushErrorObject(errors.InternalError, "Can't get block without a block argument")
+	}
+
+	// If the blockframe is from the level up, then reuse that
+	if cf.blockFrame.ep == cf.ep && cf.ep != nil && cf.blockFrame.ep.blockFrame != nil {
+		blockFrame = cf.ep.blockFrame
+	}
+
+	// Check again, just to make sure
+	if blockFrame == nil {
+		t.pushErrorObject(errors.InternalError, "Can't get block without a block argument")
+	}
+
+	blockObject := blockFrame.blockObject(t.vm, stack.data[stack.pointer-1])
+	stack.Push(blockObject)
+}
+
+// deferBlock returns the frame to run when the frame being executed finishes,
+// if the defer has a block
+func (t *Thread) deferBlock(cf *CallFrame, argCount int, blockIS *bytecode.InstructionSet) (deferred *CallFrame) {
+	stack := &t.Stack
+
+	// Allow passing a block as argument
+	// Check if we have an argument, as we don't want to splat the receiver
+	argCount, blockFrame := t.unsplatBlock(cf, argCount, blockIS)
+
+	// Deal with splat arguments
+	argCount = t.unsplatArray(argCount)
+
+	argPr := stack.pointer - argCount
+	receiverPr := argPr - 1
+
+	// Set up the blockframe for execution
+	if blockFrame != nil {
+		blockFrame.ep = cf
+		// We take a copy of the receiver as it is at the time
+		blockFrame.self = stack.data[receiverPr]
+		blockFrame.sourceLine = t.GetSourceLine()
+
+		c := newNormalCallFrame(blockFrame.instructionSet, blockFrame.instructionSet.Filename, t.GetSourceLine())
+		c.blockFrame = blockFrame
+		c.ep = blockFrame.ep
+		c.self = stack.data[receiverPr]
+		c.isBlock = true
+
+		// Populate any arguments at the time of the defer call
+		// This matches the Go semantics
+		c.initLocalsFrom(stack.data[argPr : argPr+argCount]...)
+
+		deferred = c
+	}
+
+	stack.pointer = receiverPr + 1
+	// Push something onto the stack for the next instruction
+	stack.Push(NIL)
+	return
 }
 
 func (t *Thread) unsplatArray(argCount int) int {
@@ -2,6 +2,7 @@ package vm
 
 import (
 	"math"
+	"math/big"
 	"strconv"
 
 	"github.com/robotii/lito/vm/classes"
@@ -29,11 +30,16 @@ var integerInstanceMethods = []*BuiltinMethodObject{
 
 			switch rightObject := args[0].(type) {
 			case IntegerObject:
-				return IntegerObject(int(i) + int(rightObject))
+				if sum, ok := addInt(int(i), int(rightObject)); ok {
+					return IntegerObject(sum)
+				}
+				return integerResult(new(big.Int).Add(big.NewInt(int64(i)), big.NewInt(int64(rightObject))))
+			case *BigIntegerObject:
+				return integerResult(new(big.Int).Add(big.NewInt(int64(i)), rightObject.value))
 			case FloatObject:
 				return FloatObject(i.floatValue() + float64(rightObject))
 			}
-			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+			return coerceOperator(t, "+", receiver, args[0])
 		},
 		Primitive: true,
 	},
@@ -48,13 +54,15 @@ var integerInstanceMethods = []*BuiltinMethodObject{
 					return t.vm.InitErrorObject(t, errors.ZeroDivisionError, errors.DividedByZero)
 				}
 				return IntegerObject(int(i) % int(rightObject))
+			case *BigIntegerObject:
+				return integerResult(new(big.Int).Rem(big.NewInt(int64(i)), rightObject.value))
 			case FloatObject:
 				if float64(rightObject) == 0 {
 					return t.vm.InitErrorObject(t, errors.ZeroDivisionError, errors.DividedByZero)
 				}
 				return FloatObject(math.Mod(i.floatValue(), float64(rightObject)))
 			}
-			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+			return coerceOperator(t, "%", receiver, args[0])
 		},
 		Primitive: true,
 	},
@@ -65,11 +73,16 @@ var integerInstanceMethods = []*BuiltinMethodObject{
 
 			switch rightObject := args[0].(type) {
 			case IntegerObject:
-				return IntegerObject(int(i) - int(rightObject))
+				if diff, ok := subInt(int(i), int(rightObject)); ok {
+					return IntegerObject(diff)
+				}
+				return integerResult(new(big.Int).Sub(big.NewInt(int64(i)), big.NewInt(int64(rightObject))))
+			case *BigIntegerObject:
+				return integerResult(new(big.Int).Sub(big.NewInt(int64(i)), rightObject.value))
 			case FloatObject:
 				return FloatObject(i.floatValue() - float64(rightObject))
 			}
-			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+			return coerceOperator(t, "-", receiver, args[0])
 		},
 		Primitive: true,
 	},
@@ -80,11 +93,16 @@ var integerInstanceMethods = []*BuiltinMethodObject{
 
 			switch rightObject := args[0].(type) {
 			case IntegerObject:
-				return IntegerObject(int(i) * int(rightObject))
+				if product, ok := mulInt(int(i), int(rightObject)); ok {
+					return IntegerObject(product)
+				}
+				return integerResult(new(big.Int).Mul(big.NewInt(int64(i)), big.NewInt(int64(rightObject))))
+			case *BigIntegerObject:
+				return integerResult(new(big.Int).Mul(big.NewInt(int64(i)), rightObject.value))
 			case FloatObject:
 				return FloatObject(i.floatValue() * float64(rightObject))
 			}
-			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+			return coerceOperator(t, "*", receiver, args[0])
 		},
 		Primitive: true,
 	},
@@ -95,11 +113,16 @@ var integerInstanceMethods = []*BuiltinMethodObject{
 
 			switch rightObject := args[0].(type) {
 			case IntegerObject:
-				return IntegerObject(int(math.Pow(float64(i), float64(rightObject))))
+				if rightObject < 0 {
+					return IntegerObject(int(math.Pow(float64(i), float64(rightObject))))
+				}
+				return powInt(int(i), int(rightObject))
+			case *BigIntegerObject:
+				return t.vm.InitErrorObject(t, errors.ArgumentError, "Exponent is too large: %s", rightObject.ToString(t))
 			case FloatObject:
 				return FloatObject(math.Pow(i.floatValue(), float64(rightObject)))
 			}
-			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+			return coerceOperator(t, "**", receiver, args[0])
 		},
 		Primitive: true,
 	},
@@ -113,14 +136,19 @@ var integerInstanceMethods = []*BuiltinMethodObject{
 				if int(rightObject) == 0 {
 					return t.vm.InitErrorObject(t, errors.ZeroDivisionError, errors.DividedByZero)
 				}
+				if int(i) == math.MinInt && rightObject == -1 {
+					return integerResult(new(big.Int).Neg(big.NewInt(int64(i))))
+				}
 				return IntegerObject(int(i) / int(rightObject))
+			case *BigIntegerObject:
+				return integerResult(new(big.Int).Quo(big.NewInt(int64(i)), rightObject.value))
 			case FloatObject:
 				if float64(rightObject) == 0 {
 					return t.vm.InitErrorObject(t, errors.ZeroDivisionError, errors.DividedByZero)
 				}
 				return FloatObject(i.floatValue() / float64(rightObject))
 			}
-			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+			return coerceOperator(t, "/", receiver, args[0])
 		},
 		Primitive: true,
 	},
@@ -132,10 +160,13 @@ var integerInstanceMethods = []*BuiltinMethodObject{
 			switch rightObject := args[0].(type) {
 			case IntegerObject:
 				return BooleanObject(int(i) > int(rightObject))
+			case *BigIntegerObject:
+				c, _ := rightObject.compare(i)
+				return BooleanObject(c < 0)
 			case FloatObject:
 				return BooleanObject(i.floatValue() > float64(rightObject))
 			}
-			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+			return coerceOperator(t, ">", receiver, args[0])
 		},
 		Primitive: true,
 	},
@@ -147,10 +178,13 @@ var integerInstanceMethods = []*BuiltinMethodObject{
 			switch rightObject := args[0].(type) {
 			case IntegerObject:
 				return BooleanObject(int(i) >= int(rightObject))
+			case *BigIntegerObject:
+				c, _ := rightObject.compare(i)
+				return BooleanObject(c <= 0)
 			case FloatObject:
 				return BooleanObject(i.floatValue() >= float64(rightObject))
 			}
-			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+			return coerceOperator(t, ">=", receiver, args[0])
 		},
 		Primitive: true,
 	},
@@ -162,10 +196,13 @@ var integerInstanceMethods = []*BuiltinMethodObject{
 			switch arg := args[0].(type) {
 			case IntegerObject:
 				return BooleanObject(int(i) < int(arg))
+			case *BigIntegerObject:
+				c, _ := arg.compare(i)
+				return BooleanObject(c > 0)
 			case FloatObject:
 				return BooleanObject(i.floatValue() < float64(arg))
 			}
-			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+			return coerceOperator(t, "<", receiver, args[0])
 		},
 		Primitive: true,
 	},
@@ -177,10 +214,54 @@ var integerInstanceMethods = []*BuiltinMethodObject{
 			switch rightObject := args[0].(type) {
 			case IntegerObject:
 				return BooleanObject(int(i) <= int(rightObject))
+			case *BigIntegerObject:
+				c, _ := rightObject.compare(i)
+				return BooleanObject(c >= 0)
 			case FloatObject:
 				return BooleanObject(i.floatValue() <= float64(rightObject))
 			}
-			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+			return coerceOperator(t, "<=", receiver, args[0])
+		},
+		Primitive: true,
+	},
+	{
+		Name: "&",
+		Fn: integerBitwise(func(l, r int) int {
+			return l & r
+		}, (*big.Int).And),
+		Primitive: true,
+	},
+	{
+		Name: "|",
+		Fn: integerBitwise(func(l, r int) int {
+			return l | r
+		}, (*big.Int).Or),
+		Primitive: true,
+	},
+	{
+		Name: "^",
+		Fn: integerBitwise(func(l, r int) int {
+			return l ^ r
+		}, (*big.Int).Xor),
+		Primitive: true,
+	},
+	{
+		Name:      "<<",
+		Fn:        integerShift(true),
+		Primitive: true,
+	},
+	{
+		Name:      ">>",
+		Fn:        integerShift(false),
+		Primitive: true,
+	},
+	{
+		Name: "~",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return ^receiver.(IntegerObject)
 		},
 		Primitive: true,
 	},
@@ -205,13 +286,22 @@ var integerInstanceMethods = []*BuiltinMethodObject{
 		Primitive: true,
 	},
 	{
+		// Returns the integer as a string, in the given base from 2 to 36 if one is given
 		Name: "string",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
-			if len(args) != 0 {
-				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			if len(args) > 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
 			}
 			intObj := receiver.(IntegerObject)
-			return StringObject(strconv.Itoa(int(intObj)))
+			if len(args) == 0 {
+				return StringObject(strconv.Itoa(int(intObj)))
+			}
+
+			base, err := numericBase(t, args[0])
+			if err != nil {
+				return err
+			}
+			return StringObject(strconv.FormatInt(int64(intObj), base))
 		},
 		Primitive: true,
 	},
@@ -251,9 +341,96 @@ func initIntegerClass(vm *VM) *RClass {
 		InstanceMethods(integerInstanceMethods).
 		SetConstant("MAX_INT", IntegerObject(math.MaxInt64)).
 		SetConstant("MIN_INT", IntegerObject(math.MinInt64))
+	vm.objectClass.SetClassConstant(initBigIntegerClass(vm))
 	return intClass
 }
 
+// integerBitwise returns a method applying a bitwise operator to two Integers,
+// using bigOp when the argument is a BigInteger
+func integerBitwise(op func(l, r int) int, bigOp func(z, l, r *big.Int) *big.Int) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 1 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+		}
+		i := receiver.(IntegerObject)
+		switch right := args[0].(type) {
+		case IntegerObject:
+			return IntegerObject(op(int(i), int(right)))
+		case *BigIntegerObject:
+			return integerResult(bigOp(new(big.Int), big.NewInt(int64(i)), right.value))
+		}
+		return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.IntegerClass, args[0].Class().Name)
+	}
+}
+
+// integerShift returns a method shifting an Integer or BigInteger left or right.
+// Shifting by a negative amount shifts in the other direction.
+func integerShift(left bool) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 1 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+		}
+		right, ok := args[0].(IntegerObject)
+		if !ok {
+			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.IntegerClass, args[0].Class().Name)
+		}
+
+		n := int(right)
+		if n < 0 {
+			left, n = !left, -n
+		}
+		if i, ok := receiver.(IntegerObject); ok {
+			if !left {
+				return IntegerObject(int(i) >> uint(n))
+			}
+			if shifted := int(i) << uint(n); n < strconv.IntSize && shifted>>uint(n) == int(i) {
+				return IntegerObject(shifted)
+			}
+		}
+
+		b, _ := bigIntValue(receiver)
+		if left {
+			return integerResult(new(big.Int).Lsh(b, uint(n)))
+		}
+		return integerResult(new(big.Int).Rsh(b, uint(n)))
+	}
+}
+
+// powInt raises an int to a non-negative power, promoting to a BigInteger on overflow
+func powInt(base, exp int) Object {
+	result, b, e := 1, base, exp
+	for e > 0 {
+		var ok bool
+		if e&1 == 1 {
+			if result, ok = mulInt(result, b); !ok {
+				break
+			}
+		}
+		e >>= 1
+		if e > 0 {
+			if b, ok = mulInt(b, b); !ok {
+				break
+			}
+		}
+	}
+	if e == 0 {
+		return IntegerObject(result)
+	}
+	return integerResult(new(big.Int).Exp(big.NewInt(int64(base)), big.NewInt(int64(exp)), nil))
+}
+
+// numericBase validates a base argument used when converting to and from strings
+func numericBase(t *Thread, arg Object) (int, *Error) {
+	base, ok := arg.(IntegerObject)
+	if !ok {
+		return 0, t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.IntegerClass, arg.Class().Name)
+	}
+	if base < 2 || base > 36 {
+		return 0, t.vm.InitErrorObject(t, errors.ArgumentError, "Expect base to be from 2 to 36. got: %d", int(base))
+	}
+	return int(base), nil
+}
+
 // Value returns the object
 func (i IntegerObject) Value() interface{} {
 	return int(i)
@@ -278,6 +455,9 @@ func (i IntegerObject) EqualTo(rightObject Object) bool {
 		rightValue := float64(rightObject)
 		return leftValue == rightValue
 
+	case *BigIntegerObject, *DecimalObject, *RationalObject:
+		return rightObject.EqualTo(i)
+
 	default:
 		return false
 	}
@@ -309,6 +489,9 @@ func (i IntegerObject) lessThan(arg Object) bool {
 		return int(i) < int(rightObject)
 	case FloatObject:
 		return i.floatValue() < float64(rightObject)
+	case *BigIntegerObject:
+		c, _ := rightObject.compare(i)
+		return c > 0
 	}
 	return false
 }
@@ -1,12 +1,21 @@
 package vm
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
+	"strings"
 
 	"github.com/robotii/lito/vm/classes"
 	"github.com/robotii/lito/vm/errors"
 )
 
+const (
+	jsonParseErrorFormat    = "Can't parse JSON at line %d, column %d: %s"
+	jsonGenerateErrorFormat = "Can't generate JSON from %s: %s"
+	jsonDefaultIndent       = "  "
+)
+
 var jsonClassMethods = []*BuiltinMethodObject{
 	{
 		Name:      "new",
@@ -14,6 +23,9 @@ var jsonClassMethods = []*BuiltinMethodObject{
 		Primitive: true,
 	},
 	{
+		// Parses a JSON string into the equivalent Lito objects.
+		// Whole numbers are returned as Integers, all other numbers as Floats.
+		// A JSONError is raised if the string is not valid JSON.
 		Name: "parse",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
 			if len(args) != 1 {
@@ -25,23 +37,297 @@ var jsonClassMethods = []*BuiltinMethodObject{
 				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
 			}
 
-			var o interface{}
-			jsonString := string(j)
-			jsonBytes := []byte(jsonString)
-			if err := json.Unmarshal(jsonBytes, &o); err != nil {
-				return t.vm.InitErrorObject(t, errors.InternalError, "Can't parse string `%s` as json: %s", jsonString, err.Error())
+			input := []byte(j)
+			dec := newJSONDecoder(bytes.NewReader(input))
+			o, err := decodeJSONValue(dec)
+			if err == nil {
+				// Only whitespace may follow the value
+				if _, err = dec.Token(); err == io.EOF {
+					return t.vm.InitObjectFromGoType(o)
+				}
+				if err == nil {
+					// Let the standard decoder describe what follows the value
+					err = json.Unmarshal(input, new(json.RawMessage))
+				}
 			}
-			return t.vm.InitObjectFromGoType(o)
+			return t.vm.initJSONError(t, input, jsonErrorOffset(dec, err), err)
 		},
 		Primitive: true,
 	},
+	{
+		// Decodes a single JSON value from a File.
+		// The file is read as a stream, so it is not necessary to load it into memory first.
+		Name: "load",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			f, ok := args[0].(*FileObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.FileClass, args[0].Class().Name)
+			}
+
+			start, _ := f.File.Seek(0, io.SeekCurrent)
+			dec := newJSONDecoder(f.File)
+			o, err := decodeJSONValue(dec)
+			if err != nil {
+				return t.vm.initJSONFileError(t, f, start, jsonErrorOffset(dec, err), err)
+			}
+			seekPastJSON(f, start, dec)
+			return t.vm.InitObjectFromGoType(o)
+		},
+	},
+	{
+		// Decodes each JSON value in a File in turn, yielding it to the block.
+		// This is useful for newline-delimited JSON, or files that are too large to load at once.
+		// Returns the number of values decoded.
+		Name: "each",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			f, ok := args[0].(*FileObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.FileClass, args[0].Class().Name)
+			}
+
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			start, _ := f.File.Seek(0, io.SeekCurrent)
+			dec := newJSONDecoder(f.File)
+			count := 0
+			for dec.More() {
+				o, err := decodeJSONValue(dec)
+				if err != nil {
+					return t.vm.initJSONFileError(t, f, start, jsonErrorOffset(dec, err), err)
+				}
+				count++
+				if blockFrame.IsEmpty() {
+					continue
+				}
+				t.Yield(blockFrame, t.vm.InitObjectFromGoType(o))
+				if blockFrame.IsRemoved() {
+					break
+				}
+			}
+			seekPastJSON(f, start, dec)
+			return IntegerObject(count)
+		},
+	},
+	{
+		// Generates a JSON string from the given object.
+		// Pass `pretty: true` to indent the output, and `indent:` to choose the indentation,
+		// either as a number of spaces or as a string.
+		Name: "generate",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			options, args := t.keywordArguments(args)
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			pretty, indent, err := jsonGenerateOptions(t, options)
+			if err != nil {
+				return err
+			}
+
+			var out bytes.Buffer
+			var jsonErr error
+			src := []byte(args[0].ToJSON(t))
+			if pretty {
+				jsonErr = json.Indent(&out, src, "", indent)
+			} else {
+				jsonErr = json.Compact(&out, src)
+			}
+			if jsonErr != nil {
+				return t.vm.InitErrorObject(t, errors.JSONError, jsonGenerateErrorFormat, args[0].Class().Name, jsonErr.Error())
+			}
+			return StringObject(out.String())
+		},
+	},
 }
 
 var jsonInstanceMethods = []*BuiltinMethodObject{}
 
+// jsonErrorInstanceMethods give access to the position of a JSON parse error
+var jsonErrorInstanceMethods = []*BuiltinMethodObject{
+	generateGetMethod("line"),
+	generateGetMethod("column"),
+	generateGetMethod("offset"),
+}
+
 func initJSONClass(vm *VM) {
 	class := vm.InitClass("JSON").
 		ClassMethods(jsonClassMethods).
 		InstanceMethods(jsonInstanceMethods)
 	vm.TopLevelClass(classes.ObjectClass).SetClassConstant(class)
+	vm.objectClass.getClassConstant(errors.JSONError).InstanceMethods(jsonErrorInstanceMethods)
+}
+
+// jsonGenerateOptions reads the `pretty:` and `indent:` options
+func jsonGenerateOptions(t *Thread, options map[string]Object) (pretty bool, indent string, err *Error) {
+	indent = jsonDefaultIndent
+	for name, value := range options {
+		switch name {
+		case "pretty":
+			b, ok := value.(BooleanObject)
+			if !ok {
+				return false, "", t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.BooleanClass, value.Class().Name)
+			}
+			pretty = bool(b)
+		case "indent":
+			switch v := value.(type) {
+			case IntegerObject:
+				if v < 0 {
+					return false, "", t.vm.InitErrorObject(t, errors.ArgumentError, errors.NegativeValue, int(v))
+				}
+				indent = strings.Repeat(" ", int(v))
+			case StringObject:
+				indent = string(v)
+			default:
+				return false, "", t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Integer or String", value.Class().Name)
+			}
+		default:
+			return false, "", t.vm.InitErrorObject(t, errors.ArgumentError, "Unknown option: %s", name)
+		}
+	}
+	return pretty, indent, nil
+}
+
+func newJSONDecoder(r io.Reader) *json.Decoder {
+	dec := json.NewDecoder(r)
+	// Decode numbers as json.Number so that integers and floats can be told apart
+	dec.UseNumber()
+	return dec
+}
+
+func decodeJSONValue(dec *json.Decoder) (interface{}, error) {
+	var raw json.RawMessage
+	err := dec.Decode(&raw)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	// The value is known to be valid, so it is decoded again a token at a time
+	// to keep the keys of objects in order
+	return decodeOrderedJSON(newJSONDecoder(bytes.NewReader(raw)))
+}
+
+// decodeOrderedJSON decodes the next value, returning objects as an orderedMap
+func decodeOrderedJSON(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		m := newOrderedMap()
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrderedJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			m.set(key.(string), value)
+		}
+		_, err = dec.Token()
+		return m, err
+	case json.Delim('['):
+		a := []interface{}{}
+		for dec.More() {
+			value, err := decodeOrderedJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, value)
+		}
+		_, err = dec.Token()
+		return a, err
+	}
+	return tok, nil
+}
+
+// seekPastJSON moves the file to just after the values decoded, as the decoder reads ahead
+// of them into its buffer. Files which can't seek, such as Stdin, are left where they are.
+func seekPastJSON(f *FileObject, start int64, dec *json.Decoder) {
+	f.File.Seek(start+dec.InputOffset(), io.SeekStart)
+}
+
+// jsonErrorOffset returns the number of bytes read up to and including the one that caused the error
+func jsonErrorOffset(dec *json.Decoder, err error) int64 {
+	if e, ok := err.(*json.SyntaxError); ok {
+		return e.Offset
+	}
+	// The input ended early, so the error is just past the end
+	return dec.InputOffset() + 1
+}
+
+// jsonPosition converts an error offset into a line and column
+func jsonPosition(input []byte, offset int64) (line, column int) {
+	if offset < int64(len(input)) {
+		input = input[:offset]
+	}
+	line = bytes.Count(input, []byte("\n")) + 1
+	column = int(offset) - bytes.LastIndexByte(input, '\n') - 1
+	return
+}
+
+// initJSONError returns a JSONError recording where in the input the error occurred
+func (vm *VM) initJSONError(t *Thread, input []byte, offset int64, err error) *Error {
+	line, column := jsonPosition(input, offset)
+	e := vm.InitErrorObject(t, errors.JSONError, jsonParseErrorFormat, line, column, err.Error())
+	e.SetVariable("@line", IntegerObject(line))
+	e.SetVariable("@column", IntegerObject(column))
+	e.SetVariable("@offset", IntegerObject(int(offset)))
+	return e
+}
+
+// initJSONFileError reads back the part of the file that was decoded in order to work
+// out the position of the error. Files that cannot be read again, such as Stdin, are
+// treated as if the error occurred on the first line.
+func (vm *VM) initJSONFileError(t *Thread, f *FileObject, start, offset int64, err error) *Error {
+	input := make([]byte, offset)
+	n, _ := f.File.ReadAt(input, start)
+	return vm.initJSONError(t, input[:n], offset, err)
+}
+
+// serialisableGoValue converts an object into plain Go values by way of its JSON,
+// so that other data formats respect the same `to_json` and `as_json` hooks
+func serialisableGoValue(t *Thread, obj Object) (interface{}, error) {
+	var v interface{}
+	dec := newJSONDecoder(strings.NewReader(obj.ToJSON(t)))
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return jsonNumbersToGo(v), nil
+}
+
+// jsonNumbersToGo replaces each json.Number with an int or float64
+func jsonNumbersToGo(value interface{}) interface{} {
+	switch val := value.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return int(i)
+		}
+		f, _ := val.Float64()
+		return f
+	case []interface{}:
+		for i, elem := range val {
+			val[i] = jsonNumbersToGo(elem)
+		}
+	case map[string]interface{}:
+		for k, elem := range val {
+			val[k] = jsonNumbersToGo(elem)
+		}
+	}
+	return value
 }
@@ -0,0 +1,244 @@
+package vm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+// LazyObject is a pipeline of steps over a source that has each.
+// Nothing is computed until the pipeline is iterated, and each value passes through
+// all of the steps before the next one is taken from the source, so infinite
+// sources such as Generators can be used.
+type LazyObject struct {
+	BaseObj
+	source Object
+	steps  []lazyStep
+}
+
+// lazyStep is a single step of a Lazy pipeline
+type lazyStep struct {
+	name  string
+	block *BlockObject
+	n     int
+}
+
+var lazyClassMethods = []*BuiltinMethodObject{
+	{
+		Name:      "new",
+		Fn:        NoSuchMethod("new"),
+		Primitive: true,
+	},
+}
+
+var lazyInstanceMethods = []*BuiltinMethodObject{
+	{
+		Name: "drop",
+		Fn:   lazyCountStep("drop"),
+	},
+	{
+		Name: "drop_while",
+		Fn:   lazyBlockStep("drop_while"),
+	},
+	{
+		// Runs the pipeline, yielding the values which come out of it
+		Name: "each",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			l := receiver.(*LazyObject)
+			l.run(t, func(values []Object) bool {
+				t.Yield(blockFrame, values...)
+				return !blockFrame.IsRemoved()
+			})
+			return l
+		},
+	},
+	{
+		Name: "filter",
+		Fn:   lazyBlockStep("filter"),
+	},
+	{
+		// Runs the pipeline, returning an Array of the values which come out of it
+		Name: "force",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return InitArrayObject(enumToArray(t, receiver))
+		},
+	},
+	{
+		Name: "lazy",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return receiver
+		},
+	},
+	{
+		Name: "map",
+		Fn:   lazyBlockStep("map"),
+	},
+	{
+		Name: "reject",
+		Fn:   lazyBlockStep("reject"),
+	},
+	{
+		Name: "take",
+		Fn:   lazyCountStep("take"),
+	},
+	{
+		Name: "take_while",
+		Fn:   lazyBlockStep("take_while"),
+	},
+}
+
+func initLazyClass(vm *VM) *RClass {
+	return vm.InitClass(classes.LazyClass).
+		ClassMethods(lazyClassMethods).
+		InstanceMethods(lazyInstanceMethods).
+		include(enumerableModule)
+}
+
+func newLazyObject(vm *VM, source Object) *LazyObject {
+	return &LazyObject{
+		BaseObj: BaseObj{class: vm.TopLevelClass(classes.LazyClass)},
+		source:  source,
+	}
+}
+
+// lazyBlockStep returns a method adding a step which uses the block to the pipeline
+func lazyBlockStep(name string) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 0 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+		}
+		blockFrame := t.GetBlock()
+		if blockFrame == nil {
+			return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+		}
+		return receiver.(*LazyObject).with(lazyStep{name: name, block: blockFrame.blockObject(t.vm, blockFrame.self)})
+	}
+}
+
+// lazyCountStep returns a method adding a step which counts values to the pipeline
+func lazyCountStep(name string) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		n, err := enumCount(t, args)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.NegativeValue, n)
+		}
+		return receiver.(*LazyObject).with(lazyStep{name: name, n: n})
+	}
+}
+
+// with returns a copy of the pipeline with the step added
+func (l *LazyObject) with(step lazyStep) *LazyObject {
+	steps := make([]lazyStep, len(l.steps), len(l.steps)+1)
+	copy(steps, l.steps)
+	return &LazyObject{
+		BaseObj: BaseObj{class: l.class},
+		source:  l.source,
+		steps:   append(steps, step),
+	}
+}
+
+// run passes the values of the source through the steps, calling fn with those which
+// come out of the pipeline until it returns false, or until the pipeline ends
+func (l *LazyObject) run(t *Thread, fn func(values []Object) bool) {
+	frames := make([]*CallFrame, len(l.steps))
+	counts := make([]int, len(l.steps))
+	for i, step := range l.steps {
+		if step.block != nil {
+			frames[i] = step.block.asCallFrame(t)
+		}
+		// Nothing can come out of a pipeline which takes no values
+		if step.name == "take" && step.n == 0 {
+			return
+		}
+	}
+
+	enumerate(t, l.source, func(values []Object) bool {
+		// last is set when a take step has seen all the values it needs
+		last := false
+		for i, step := range l.steps {
+			switch step.name {
+			case "map":
+				values = []Object{t.Yield(frames[i], values...)}
+			case "filter":
+				if !t.Yield(frames[i], values...).IsTruthy() {
+					return !last
+				}
+			case "reject":
+				if t.Yield(frames[i], values...).IsTruthy() {
+					return !last
+				}
+			case "take_while":
+				if !t.Yield(frames[i], values...).IsTruthy() {
+					return false
+				}
+			case "drop_while":
+				// A count of 1 marks that the values are no longer being dropped
+				if counts[i] == 0 {
+					if t.Yield(frames[i], values...).IsTruthy() {
+						return !last
+					}
+					counts[i] = 1
+				}
+			case "take":
+				counts[i]++
+				last = last || counts[i] >= step.n
+			case "drop":
+				if counts[i] < step.n {
+					counts[i]++
+					return !last
+				}
+			}
+		}
+		return fn(values) && !last
+	})
+}
+
+// Value returns the source of the pipeline
+func (l *LazyObject) Value() interface{} {
+	return l.source
+}
+
+// ToString returns the object's name as the string format
+func (l *LazyObject) ToString(t *Thread) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "#<Lazy: %s", l.source.Inspect(t))
+	for _, step := range l.steps {
+		out.WriteString(" " + step.name)
+		if step.block == nil {
+			fmt.Fprintf(&out, "(%d)", step.n)
+		}
+	}
+	out.WriteString(">")
+	return out.String()
+}
+
+// Inspect delegates to ToString
+func (l *LazyObject) Inspect(t *Thread) string {
+	return l.ToString(t)
+}
+
+// ToJSON just delegates to ToString
+func (l *LazyObject) ToJSON(t *Thread) string {
+	return l.ToString(t)
+}
+
+// EqualTo returns true if the objects are the same Lazy pipeline
+func (l *LazyObject) EqualTo(with Object) bool {
+	return l == with
+}
@@ -0,0 +1,297 @@
+package vm
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+var mathClassMethods = []*BuiltinMethodObject{
+	{
+		Name:      "new",
+		Fn:        NoSuchMethod("new"),
+		Primitive: true,
+	},
+	{
+		Name: "abs",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			switch n := args[0].(type) {
+			case IntegerObject:
+				if n < 0 {
+					// The negation of the smallest Integer is too large for an Integer
+					return integerResult(new(big.Int).Neg(big.NewInt(int64(n))))
+				}
+				return n
+			case FloatObject:
+				return FloatObject(math.Abs(float64(n)))
+			case *BigIntegerObject:
+				return integerResult(new(big.Int).Abs(n.value))
+			case *DecimalObject:
+				return t.vm.initDecimalObject(new(big.Int).Abs(n.value), n.scale, n.rounding)
+			case *RationalObject:
+				return t.vm.initRationalObject(new(big.Rat).Abs(n.value))
+			}
+			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+		},
+		Primitive: true,
+	},
+	{
+		Name:      "acos",
+		Fn:        mathFunc(math.Acos),
+		Primitive: true,
+	},
+	{
+		Name:      "asin",
+		Fn:        mathFunc(math.Asin),
+		Primitive: true,
+	},
+	{
+		Name:      "atan",
+		Fn:        mathFunc(math.Atan),
+		Primitive: true,
+	},
+	{
+		Name:      "atan2",
+		Fn:        mathFunc2(math.Atan2),
+		Primitive: true,
+	},
+	{
+		Name:      "cbrt",
+		Fn:        mathFunc(math.Cbrt),
+		Primitive: true,
+	},
+	{
+		Name:      "ceil",
+		Fn:        mathRound(math.Ceil),
+		Primitive: true,
+	},
+	{
+		Name:      "cos",
+		Fn:        mathFunc(math.Cos),
+		Primitive: true,
+	},
+	{
+		Name:      "cosh",
+		Fn:        mathFunc(math.Cosh),
+		Primitive: true,
+	},
+	{
+		Name:      "exp",
+		Fn:        mathFunc(math.Exp),
+		Primitive: true,
+	},
+	{
+		Name:      "floor",
+		Fn:        mathRound(math.Floor),
+		Primitive: true,
+	},
+	{
+		Name:      "hypot",
+		Fn:        mathFunc2(math.Hypot),
+		Primitive: true,
+	},
+	{
+		// Returns the natural logarithm, or the logarithm in the given base
+		Name: "log",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) < 1 || len(args) > 2 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentRange, 1, 2, len(args))
+			}
+			values, err := mathArgs(t, args)
+			if err != nil {
+				return err
+			}
+			if len(values) == 2 {
+				return FloatObject(math.Log(values[0]) / math.Log(values[1]))
+			}
+			return FloatObject(math.Log(values[0]))
+		},
+		Primitive: true,
+	},
+	{
+		Name:      "log10",
+		Fn:        mathFunc(math.Log10),
+		Primitive: true,
+	},
+	{
+		Name:      "log2",
+		Fn:        mathFunc(math.Log2),
+		Primitive: true,
+	},
+	{
+		Name: "max",
+		Fn: mathExtreme(func(a, b Numeric) bool {
+			return b.lessThan(a.(Object))
+		}),
+		Primitive: true,
+	},
+	{
+		Name: "min",
+		Fn: mathExtreme(func(a, b Numeric) bool {
+			return a.lessThan(b.(Object))
+		}),
+		Primitive: true,
+	},
+	{
+		Name:      "pow",
+		Fn:        mathFunc2(math.Pow),
+		Primitive: true,
+	},
+	{
+		// Rounds to the nearest Integer, or to the given number of decimal places as a Float
+		Name: "round",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) < 1 || len(args) > 2 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentRange, 1, 2, len(args))
+			}
+			n, ok := args[0].(Numeric)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 1, "Numeric", args[0].Class().Name)
+			}
+			if len(args) == 1 {
+				switch n := n.(type) {
+				case IntegerObject, *BigIntegerObject:
+					return n.(Object)
+				}
+				f := math.Round(n.floatValue())
+				if math.IsInf(f, 0) || math.IsNaN(f) {
+					return t.vm.InitErrorObject(t, errors.ArgumentError, "Can't round %s to an Integer", args[0].Inspect(t))
+				}
+				if f >= math.MinInt64 && f < math.MaxInt64 {
+					return IntegerObject(int(f))
+				}
+				// Floats this large are whole numbers, but too large for an Integer
+				i, _ := big.NewFloat(f).Int(nil)
+				return integerResult(i)
+			}
+			digits, ok := args[1].(IntegerObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 2, classes.IntegerClass, args[1].Class().Name)
+			}
+			return FloatObject(roundFloat(n.floatValue(), int(digits)))
+		},
+		Primitive: true,
+	},
+	{
+		Name:      "sin",
+		Fn:        mathFunc(math.Sin),
+		Primitive: true,
+	},
+	{
+		Name:      "sinh",
+		Fn:        mathFunc(math.Sinh),
+		Primitive: true,
+	},
+	{
+		Name:      "sqrt",
+		Fn:        mathFunc(math.Sqrt),
+		Primitive: true,
+	},
+	{
+		Name:      "tan",
+		Fn:        mathFunc(math.Tan),
+		Primitive: true,
+	},
+	{
+		Name:      "tanh",
+		Fn:        mathFunc(math.Tanh),
+		Primitive: true,
+	},
+}
+
+func initMathClass(vm *VM) *RClass {
+	return vm.InitClass(classes.MathClass).
+		ClassMethods(mathClassMethods).
+		SetConstant("PI", FloatObject(math.Pi)).
+		SetConstant("E", FloatObject(math.E))
+}
+
+// mathArgs converts the Numeric arguments to float64
+func mathArgs(t *Thread, args []Object) ([]float64, *Error) {
+	values := make([]float64, len(args))
+	for i, arg := range args {
+		n, ok := arg.(Numeric)
+		if !ok {
+			return nil, t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, i+1, "Numeric", arg.Class().Name)
+		}
+		values[i] = n.floatValue()
+	}
+	return values, nil
+}
+
+// mathFunc returns a method applying fn to a single Numeric argument
+func mathFunc(fn func(float64) float64) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 1 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+		}
+		values, err := mathArgs(t, args)
+		if err != nil {
+			return err
+		}
+		return FloatObject(fn(values[0]))
+	}
+}
+
+// mathFunc2 returns a method applying fn to two Numeric arguments
+func mathFunc2(fn func(float64, float64) float64) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 2 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 2, len(args))
+		}
+		values, err := mathArgs(t, args)
+		if err != nil {
+			return err
+		}
+		return FloatObject(fn(values[0], values[1]))
+	}
+}
+
+// mathRound returns a method rounding a Numeric argument to an Integer
+func mathRound(fn func(float64) float64) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 1 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+		}
+		if i, ok := args[0].(IntegerObject); ok {
+			return i
+		}
+		values, err := mathArgs(t, args)
+		if err != nil {
+			return err
+		}
+		return IntegerObject(int(fn(values[0])))
+	}
+}
+
+// mathExtreme returns a method choosing the argument for which better is true
+// against every other argument. A single Array argument is treated as the list of values.
+func mathExtreme(better func(a, b Numeric) bool) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) == 1 {
+			if arr, ok := args[0].(*ArrayObject); ok {
+				args = arr.Elements
+			}
+		}
+		if len(args) == 0 {
+			return NIL
+		}
+
+		var result Numeric
+		for i, arg := range args {
+			n, ok := arg.(Numeric)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, i+1, "Numeric", arg.Class().Name)
+			}
+			if result == nil || better(n, result) {
+				result = n
+			}
+		}
+		return result.(Object)
+	}
+}
@@ -10,9 +10,11 @@ func NoSuchMethod(name string) Method {
 // BuiltinMethodObject represents methods defined in go.
 type BuiltinMethodObject struct {
 	BaseObj
-	Name      string
-	Fn        Method
-	Primitive bool
+	Name       string
+	Fn         Method
+	Primitive  bool
+	Visibility Visibility
+	owner      *RClass
 }
 
 // Method is a callable function
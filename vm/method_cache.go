@@ -0,0 +1,95 @@
+package vm
+
+import (
+	"sync/atomic"
+
+	"github.com/robotii/lito/compiler/bytecode"
+)
+
+// maxCachedClasses is the number of receiver classes a call site caches methods for.
+// Call sites which see more classes than this are not cached.
+const maxCachedClasses = 4
+
+// methodTableVersion is changed whenever a method is defined or a class's ancestors
+// change, which invalidates every inline method cache
+var methodTableVersion atomic.Uint64
+
+// methodCache is the inline cache of a call site. It is replaced rather than changed,
+// so that threads running the same instructions always see a whole cache.
+type methodCache struct {
+	version     uint64
+	megamorphic bool
+	entries     []methodCacheEntry
+}
+
+type methodCacheEntry struct {
+	class *RClass
+	// classReceiver is set when the receiver is the class itself, whose class
+	// methods are found through its metaclass
+	classReceiver bool
+	method        Object
+}
+
+// invalidateMethodCaches must be called after changing a method table or a superclass
+func invalidateMethodCaches() {
+	methodTableVersion.Add(1)
+}
+
+// findMethodAt returns the method the receiver has for the call, from the call site's
+// cache if the receiver's class has been seen there since the method tables last changed
+func findMethodAt(site *bytecode.CallSite, receiver Object, methodName string, super bool) Object {
+	if site == nil || super {
+		return receiver.FindMethod(methodName, super)
+	}
+
+	class, classReceiver := receiver.Class(), false
+	if c, ok := receiver.(*RClass); ok {
+		class, classReceiver = c, true
+	} else if s, ok := receiver.(singletonObject); ok && s.singletonClass() != nil {
+		// Objects with their own methods are cached by their singleton class
+		class = s.singletonClass()
+	}
+
+	version := methodTableVersion.Load()
+	cache, _ := site.Cache.Load().(*methodCache)
+	if cache != nil && cache.version == version {
+		if cache.megamorphic {
+			return receiver.FindMethod(methodName, false)
+		}
+		for _, e := range cache.entries {
+			if e.class == class && e.classReceiver == classReceiver {
+				return e.method
+			}
+		}
+	} else {
+		cache = &methodCache{version: version}
+	}
+
+	method := receiver.FindMethod(methodName, false)
+	if method == nil {
+		// Leave missing methods to method_missing each time
+		return nil
+	}
+
+	updated := &methodCache{version: version}
+	if len(cache.entries) >= maxCachedClasses {
+		updated.megamorphic = true
+	} else {
+		updated.entries = append(append(make([]methodCacheEntry, 0, len(cache.entries)+1), cache.entries...),
+			methodCacheEntry{class: class, classReceiver: classReceiver, method: method})
+	}
+	site.Cache.Store(updated)
+	return method
+}
+
+// setMethod defines the method in the class's method table
+func (c *RClass) setMethod(name string, method Object) {
+	c.Methods[name] = method
+	invalidateMethodCaches()
+}
+
+// removeMethod removes the method from the class's method table
+func (c *RClass) removeMethod(name string) {
+	delete(c.Methods, name)
+	invalidateMethodCaches()
+}
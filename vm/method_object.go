@@ -14,12 +14,28 @@ type MethodObject struct {
 	Name           string
 	instructionSet *bytecode.InstructionSet
 	argc           int
+	Visibility     Visibility
+	// owner is the class which made the method protected or private
+	owner *RClass
+	// ep is the frame a method defined from a block runs in
+	ep *CallFrame
 }
 
 func initMethodClass(vm *VM) *RClass {
 	return vm.InitClass(classes.MethodClass)
 }
 
+// blockMethod returns a method which runs the block's instructions, with the block's environment
+func (vm *VM) blockMethod(name string, block *BlockObject) *MethodObject {
+	return &MethodObject{
+		Name:           name,
+		argc:           len(block.instructionSet.ArgTypes.Names()),
+		instructionSet: block.instructionSet,
+		ep:             block.ep,
+		BaseObj:        BaseObj{class: vm.TopLevelClass(classes.MethodClass)},
+	}
+}
+
 // ToString returns a string representation of the method
 func (m *MethodObject) ToString(t *Thread) string {
 	var out strings.Builder
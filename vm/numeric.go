@@ -1,7 +1,28 @@
 package vm
 
+import "github.com/robotii/lito/vm/errors"
+
 // Numeric represents a class that support numeric conversion to float.
 type Numeric interface {
 	floatValue() float64
 	lessThan(object Object) bool
 }
+
+// coerceOperator applies an operator to a receiver and an argument of a type it does not know.
+// Following the coerce protocol, the argument's coerce method is called with the receiver and
+// must return an Array of the two converted operands, which the operator is then applied to.
+func coerceOperator(t *Thread, operator string, receiver, arg Object) Object {
+	if arg.FindMethod("coerce", false) == nil {
+		return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", arg.Class().Name)
+	}
+
+	coerced := t.CallMethod(arg, "coerce", receiver)
+	if err, ok := coerced.(*Error); ok {
+		return err
+	}
+	pair, ok := coerced.(*ArrayObject)
+	if !ok || len(pair.Elements) != 2 {
+		return t.vm.InitErrorObject(t, errors.TypeError, "Expect coerce to return an Array of 2 elements. got: %s", coerced.Inspect(t))
+	}
+	return t.CallMethod(pair.Elements[0], operator, pair.Elements[1])
+}
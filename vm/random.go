@@ -0,0 +1,277 @@
+package vm
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+// RandomObject is a pseudo-random number generator.
+// Generators created with the same seed produce the same sequence of values.
+type RandomObject struct {
+	BaseObj
+	seed  int64
+	mutex sync.Mutex
+	rand  *rand.Rand
+}
+
+// defaultRandom is used when the methods are called on the Random class itself
+var defaultRandom = newRandomObject(nil, randomSeed())
+
+// The methods are class methods so that they may be called on the Random class, using
+// the default generator, as well as on a seeded instance.
+var randomClassMethods = []*BuiltinMethodObject{
+	{
+		// Creates a generator with the given Integer seed, or a random seed if none is given.
+		Name: "new",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) > 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
+			}
+
+			seed := randomSeed()
+			if len(args) == 1 {
+				s, ok := args[0].(IntegerObject)
+				if !ok {
+					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.IntegerClass, args[0].Class().Name)
+				}
+				seed = int64(s)
+			}
+			return newRandomObject(t.vm.loadConstant("Random", false), seed)
+		},
+	},
+	{
+		Name: "seed",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return IntegerObject(randomSource(receiver).seed)
+		},
+		Primitive: true,
+	},
+	{
+		// Returns a random Integer from 0 up to, but not including, the given Integer,
+		// or from within the given Range.
+		Name: "int",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			var min, n int
+			switch arg := args[0].(type) {
+			case IntegerObject:
+				min, n = 0, int(arg)
+			case *RangeObject:
+				min, n = arg.Start, arg.End-arg.Start
+				if !arg.Exclusive {
+					n++
+				}
+			default:
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Integer or Range", args[0].Class().Name)
+			}
+			if n <= 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, "Expect a non-empty range. got: %s", args[0].Inspect(t))
+			}
+
+			r := randomSource(receiver)
+			r.mutex.Lock()
+			defer r.mutex.Unlock()
+			return IntegerObject(min + r.rand.Intn(n))
+		},
+		Primitive: true,
+	},
+	{
+		// Returns a random Float from 0.0 up to, but not including, 1.0 or the given maximum.
+		Name: "float",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) > 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
+			}
+
+			max := 1.0
+			if len(args) == 1 {
+				n, ok := args[0].(Numeric)
+				if !ok {
+					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+				}
+				max = n.floatValue()
+			}
+
+			r := randomSource(receiver)
+			r.mutex.Lock()
+			defer r.mutex.Unlock()
+			return FloatObject(r.rand.Float64() * max)
+		},
+		Primitive: true,
+	},
+	{
+		// Returns a new Array with the elements of the given Array in a random order.
+		Name: "shuffle",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			arr, ok := args[0].(*ArrayObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.ArrayClass, args[0].Class().Name)
+			}
+
+			elems := make([]Object, len(arr.Elements))
+			copy(elems, arr.Elements)
+
+			r := randomSource(receiver)
+			r.mutex.Lock()
+			defer r.mutex.Unlock()
+			r.rand.Shuffle(len(elems), func(i, j int) {
+				elems[i], elems[j] = elems[j], elems[i]
+			})
+			return InitArrayObject(elems)
+		},
+		Primitive: true,
+	},
+	{
+		// Returns a random element of the given Array, or nil if it is empty.
+		// If a count is given, returns an Array of that many elements, each chosen at most once.
+		Name: "sample",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) < 1 || len(args) > 2 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentRange, 1, 2, len(args))
+			}
+
+			arr, ok := args[0].(*ArrayObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 1, classes.ArrayClass, args[0].Class().Name)
+			}
+
+			r := randomSource(receiver)
+			r.mutex.Lock()
+			defer r.mutex.Unlock()
+
+			if len(args) == 1 {
+				if len(arr.Elements) == 0 {
+					return NIL
+				}
+				return arr.Elements[r.rand.Intn(len(arr.Elements))]
+			}
+
+			count, ok := args[1].(IntegerObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 2, classes.IntegerClass, args[1].Class().Name)
+			}
+			if count < 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.NegativeSecondValue, int(count))
+			}
+
+			n := len(arr.Elements)
+			if int(count) < n {
+				n = int(count)
+			}
+			elems := make([]Object, n)
+			for i, j := range r.rand.Perm(len(arr.Elements))[:n] {
+				elems[i] = arr.Elements[j]
+			}
+			return InitArrayObject(elems)
+		},
+		Primitive: true,
+	},
+	{
+		// Returns the given number of cryptographically secure random Bytes.
+		// These do not come from the seeded generator.
+		Name: "bytes",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			n, ok := args[0].(IntegerObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.IntegerClass, args[0].Class().Name)
+			}
+			if n < 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.NegativeValue, int(n))
+			}
+
+			b := make([]byte, int(n))
+			if _, err := crand.Read(b); err != nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, err.Error())
+			}
+			return t.vm.InitBytesObject(b)
+		},
+	},
+	{
+		// Returns a version 4 UUID, generated from cryptographically secure random bytes.
+		Name: "uuid",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+
+			var b [16]byte
+			if _, err := crand.Read(b[:]); err != nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, err.Error())
+			}
+			b[6] = (b[6] & 0x0f) | 0x40
+			b[8] = (b[8] & 0x3f) | 0x80
+			return StringObject(fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]))
+		},
+	},
+}
+
+var randomInstanceMethods = []*BuiltinMethodObject{}
+
+func newRandomObject(class *RClass, seed int64) *RandomObject {
+	return &RandomObject{
+		BaseObj: BaseObj{class: class},
+		seed:    seed,
+		rand:    rand.New(rand.NewSource(seed)),
+	}
+}
+
+func initRandomClass(vm *VM) {
+	vm.objectClass.SetClassConstant(vm.InitClass("Random").
+		ClassMethods(randomClassMethods).
+		InstanceMethods(randomInstanceMethods))
+}
+
+// randomSource returns the generator to use for a method called on the receiver
+func randomSource(receiver Object) *RandomObject {
+	if r, ok := receiver.(*RandomObject); ok {
+		return r
+	}
+	return defaultRandom
+}
+
+// randomSeed returns a seed from the secure random source
+func randomSeed() int64 {
+	var b [8]byte
+	_, _ = crand.Read(b[:])
+	return int64(binary.LittleEndian.Uint64(b[:]))
+}
+
+// Value returns the seed
+func (r *RandomObject) Value() interface{} {
+	return r.seed
+}
+
+// ToString returns the object's name as the string format
+func (r *RandomObject) ToString(t *Thread) string {
+	return fmt.Sprintf("#<%s seed: %d>", r.class.Name, r.seed)
+}
+
+// Inspect delegates to ToString
+func (r *RandomObject) Inspect(t *Thread) string {
+	return r.ToString(t)
+}
+
+// ToJSON just delegates to ToString
+func (r *RandomObject) ToJSON(t *Thread) string {
+	return r.ToString(t)
+}
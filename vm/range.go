@@ -36,8 +36,9 @@ var rangeInstanceMethods = []*BuiltinMethodObject{
 				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
 			}
 
-			return ro.each(func(i int) {
+			return ro.each(func(i int) bool {
 				t.Yield(blockFrame, IntegerObject(i))
+				return !blockFrame.IsRemoved()
 			})
 		},
 	},
@@ -62,32 +63,6 @@ var rangeInstanceMethods = []*BuiltinMethodObject{
 		},
 		Primitive: true,
 	},
-	{
-		Name: "map",
-		Fn: func(receiver Object, t *Thread, args []Object) Object {
-			if len(args) != 0 {
-				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
-			}
-			blockFrame := t.GetBlock()
-			if blockFrame == nil {
-				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
-			}
-
-			ro := receiver.(*RangeObject)
-			el := make([]Object, 0, ro.size())
-			if blockFrame.IsEmpty() {
-				ro.each(func(i int) {
-					el = append(el, NIL)
-				})
-			} else {
-				ro.each(func(i int) {
-					el = append(el, t.Yield(blockFrame, IntegerObject(i)))
-				})
-			}
-
-			return InitArrayObject(el)
-		},
-	},
 	{
 		Name: "size",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
@@ -112,10 +87,11 @@ var rangeInstanceMethods = []*BuiltinMethodObject{
 				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.NegativeValue, step)
 			}
 
-			return ro.each(func(i int) {
+			return ro.each(func(i int) bool {
 				if (i-ro.Start)%step == 0 {
 					t.Yield(blockFrame, IntegerObject(i))
 				}
+				return !blockFrame.IsRemoved()
 			})
 		},
 	},
@@ -125,8 +101,9 @@ var rangeInstanceMethods = []*BuiltinMethodObject{
 			ro := receiver.(*RangeObject)
 			el := make([]Object, 0, ro.size())
 
-			ro.each(func(i int) {
+			ro.each(func(i int) bool {
 				el = append(el, IntegerObject(i))
+				return true
 			})
 
 			return InitArrayObject(el)
@@ -154,7 +131,8 @@ func initRangeObject(vm *VM, start, end int, exclusive bool) *RangeObject {
 func initRangeClass(vm *VM) *RClass {
 	return vm.InitClass(classes.RangeClass).
 		ClassMethods(rangeClassMethods).
-		InstanceMethods(rangeInstanceMethods)
+		InstanceMethods(rangeInstanceMethods).
+		include(enumerableModule)
 }
 
 // ToString returns the object's name as the string format
@@ -180,17 +158,18 @@ func (ro *RangeObject) Value() interface{} {
 	return ro.ToString(nil)
 }
 
-func (ro *RangeObject) each(f func(int)) *RangeObject {
+// each calls f with each integer in the range in turn, until f returns false.
+// The last integer is compared with rather than stepped past, so ranges ending at
+// the largest or smallest Integer don't overflow.
+func (ro *RangeObject) each(f func(int) bool) *RangeObject {
 	inc := 1
-	if ro.End-ro.Start < 0 {
+	if ro.End < ro.Start {
 		inc = -1
 	}
-	end := ro.End + inc
-	if ro.Exclusive {
-		end = ro.End
-	}
-	for i := ro.Start; i != end; i += inc {
-		f(i)
+	for i := ro.Start; !ro.Exclusive || i != ro.End; i += inc {
+		if !f(i) || i == ro.End {
+			break
+		}
 	}
 	return ro
 }
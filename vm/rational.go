@@ -0,0 +1,360 @@
+package vm
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+// RationalObject is an exact fraction of two integers, always kept in its lowest terms
+type RationalObject struct {
+	BaseObj
+	value *big.Rat
+}
+
+var rationalClassMethods = []*BuiltinMethodObject{
+	{
+		// Creates a Rational from a numerator and optional denominator, or from a String
+		// such as "3/4" or "0.75". A Float is converted exactly.
+		Name: "new",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) < 1 || len(args) > 2 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentRange, 1, 2, len(args))
+			}
+
+			values := make([]*big.Rat, len(args))
+			for i, arg := range args {
+				if s, ok := arg.(StringObject); ok {
+					r, ok := new(big.Rat).SetString(strings.TrimSpace(string(s)))
+					if !ok {
+						return t.vm.InitErrorObject(t, errors.ArgumentError, errors.InvalidNumericString, string(s))
+					}
+					values[i] = r
+					continue
+				}
+
+				r, ok := ratValue(arg)
+				if !ok {
+					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, i+1, "Numeric or String", arg.Class().Name)
+				}
+				values[i] = r
+			}
+
+			if len(values) == 2 {
+				if values[1].Sign() == 0 {
+					return t.vm.InitErrorObject(t, errors.ZeroDivisionError, errors.DividedByZero)
+				}
+				values[0] = new(big.Rat).Quo(values[0], values[1])
+			}
+			return t.vm.initRationalObject(values[0])
+		},
+	},
+}
+
+var rationalInstanceMethods = []*BuiltinMethodObject{
+	{
+		Name: "+",
+		Fn: rationalArithmetic("+", func(l, r *big.Rat) (*big.Rat, bool) {
+			return new(big.Rat).Add(l, r), true
+		}, func(l, r float64) float64 {
+			return l + r
+		}),
+	},
+	{
+		Name: "-",
+		Fn: rationalArithmetic("-", func(l, r *big.Rat) (*big.Rat, bool) {
+			return new(big.Rat).Sub(l, r), true
+		}, func(l, r float64) float64 {
+			return l - r
+		}),
+	},
+	{
+		Name: "*",
+		Fn: rationalArithmetic("*", func(l, r *big.Rat) (*big.Rat, bool) {
+			return new(big.Rat).Mul(l, r), true
+		}, func(l, r float64) float64 {
+			return l * r
+		}),
+	},
+	{
+		// Raises the Rational to an Integer power, or to a Float power as a Float
+		Name: "**",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			r := receiver.(*RationalObject)
+
+			switch n := args[0].(type) {
+			case IntegerObject:
+				exp := int(n)
+				if exp < 0 {
+					exp = -exp
+				}
+				e := big.NewInt(int64(exp))
+				num := new(big.Int).Exp(r.value.Num(), e, nil)
+				den := new(big.Int).Exp(r.value.Denom(), e, nil)
+				if n < 0 {
+					if num.Sign() == 0 {
+						return t.vm.InitErrorObject(t, errors.ZeroDivisionError, errors.DividedByZero)
+					}
+					num, den = den, num
+				}
+				return t.vm.initRationalObject(new(big.Rat).SetFrac(num, den))
+			case FloatObject:
+				return FloatObject(math.Pow(r.floatValue(), float64(n)))
+			}
+			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Integer or Float", args[0].Class().Name)
+		},
+	},
+	{
+		Name: "/",
+		Fn: rationalArithmetic("/", func(l, r *big.Rat) (*big.Rat, bool) {
+			if r.Sign() == 0 {
+				return nil, false
+			}
+			return new(big.Rat).Quo(l, r), true
+		}, func(l, r float64) float64 {
+			return l / r
+		}),
+	},
+	{
+		Name: ">",
+		Fn: rationalCompare(">", func(c int) bool {
+			return c > 0
+		}),
+	},
+	{
+		Name: ">=",
+		Fn: rationalCompare(">=", func(c int) bool {
+			return c >= 0
+		}),
+	},
+	{
+		Name: "<",
+		Fn: rationalCompare("<", func(c int) bool {
+			return c < 0
+		}),
+	},
+	{
+		Name: "<=",
+		Fn: rationalCompare("<=", func(c int) bool {
+			return c <= 0
+		}),
+	},
+	{
+		Name: "abs",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return t.vm.initRationalObject(new(big.Rat).Abs(receiver.(*RationalObject).value))
+		},
+	},
+	{
+		// Converts an Integer or Decimal to a Rational, and a Float to a Float,
+		// so it can be used with the receiver's operators
+		Name: "coerce",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			r := receiver.(*RationalObject)
+
+			if f, ok := args[0].(FloatObject); ok {
+				return InitArrayObject([]Object{f, FloatObject(r.floatValue())})
+			}
+			value, ok := ratValue(args[0])
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", args[0].Class().Name)
+			}
+			return InitArrayObject([]Object{t.vm.initRationalObject(value), r})
+		},
+	},
+	{
+		// Returns the Rational as a Decimal with the given scale and optional rounding mode
+		Name: "decimal",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) < 1 || len(args) > 2 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentRange, 1, 2, len(args))
+			}
+			r := receiver.(*RationalObject)
+
+			scale, ok := args[0].(IntegerObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 1, classes.IntegerClass, args[0].Class().Name)
+			}
+			if scale < 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.NegativeValue, int(scale))
+			}
+			rounding := defaultRounding
+			if len(args) == 2 {
+				mode, err := roundingMode(t, args[1])
+				if err != nil {
+					return err
+				}
+				rounding = mode
+			}
+
+			value := roundQuotient(new(big.Int).Mul(r.value.Num(), pow10(int(scale))), r.value.Denom(), rounding)
+			return t.vm.initDecimalObject(value, int(scale), rounding)
+		},
+	},
+	{
+		Name: "denominator",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return integerResult(new(big.Int).Set(receiver.(*RationalObject).value.Denom()))
+		},
+	},
+	{
+		Name: "float",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return FloatObject(receiver.(*RationalObject).floatValue())
+		},
+	},
+	{
+		// Returns the integer part of the Rational
+		Name: "int",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			r := receiver.(*RationalObject).value
+			return integerResult(new(big.Int).Quo(r.Num(), r.Denom()))
+		},
+	},
+	{
+		Name: "numerator",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return integerResult(new(big.Int).Set(receiver.(*RationalObject).value.Num()))
+		},
+	},
+	{
+		Name: "string",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return StringObject(receiver.ToString(t))
+		},
+	},
+}
+
+func (vm *VM) initRationalObject(value *big.Rat) *RationalObject {
+	return &RationalObject{
+		BaseObj: BaseObj{class: vm.TopLevelClass(classes.RationalClass)},
+		value:   value,
+	}
+}
+
+func initRationalClass(vm *VM) *RClass {
+	return vm.InitClass(classes.RationalClass).
+		ClassMethods(rationalClassMethods).
+		InstanceMethods(rationalInstanceMethods)
+}
+
+// ratValue returns the exact value of a Rational, Decimal, Integer, BigInteger or finite Float
+func ratValue(obj Object) (*big.Rat, bool) {
+	switch obj := obj.(type) {
+	case *RationalObject:
+		return obj.value, true
+	case *DecimalObject:
+		return obj.rat(), true
+	case IntegerObject, *BigIntegerObject:
+		v, _ := bigIntValue(obj)
+		return new(big.Rat).SetInt(v), true
+	case FloatObject:
+		f := float64(obj)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, false
+		}
+		return new(big.Rat).SetFloat64(f), true
+	}
+	return nil, false
+}
+
+// rationalArithmetic returns a method applying the named operator to a Rational and a number.
+// The result is exact unless the number is a Float. The operator returns false if the
+// right operand is zero where that is not allowed.
+func rationalArithmetic(name string, op func(l, r *big.Rat) (*big.Rat, bool), floatOp func(l, r float64) float64) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 1 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+		}
+		r := receiver.(*RationalObject)
+
+		if f, ok := args[0].(FloatObject); ok {
+			if name == "/" && f == 0 {
+				return t.vm.InitErrorObject(t, errors.ZeroDivisionError, errors.DividedByZero)
+			}
+			return FloatObject(floatOp(r.floatValue(), float64(f)))
+		}
+		value, ok := ratValue(args[0])
+		if !ok {
+			return coerceOperator(t, name, receiver, args[0])
+		}
+		result, ok := op(r.value, value)
+		if !ok {
+			return t.vm.InitErrorObject(t, errors.ZeroDivisionError, errors.DividedByZero)
+		}
+		return t.vm.initRationalObject(result)
+	}
+}
+
+// rationalCompare returns a method comparing a Rational with a number
+func rationalCompare(name string, test func(c int) bool) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 1 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+		}
+		value, ok := ratValue(args[0])
+		if !ok {
+			return coerceOperator(t, name, receiver, args[0])
+		}
+		return BooleanObject(test(receiver.(*RationalObject).value.Cmp(value)))
+	}
+}
+
+// Value returns the big.Rat
+func (r *RationalObject) Value() interface{} {
+	return r.value
+}
+
+func (r *RationalObject) floatValue() float64 {
+	f, _ := r.value.Float64()
+	return f
+}
+
+// EqualTo returns true if the object is a number with the same value
+func (r *RationalObject) EqualTo(with Object) bool {
+	value, ok := ratValue(with)
+	return ok && r.value.Cmp(value) == 0
+}
+
+// ToString returns the Rational as numerator/denominator
+func (r *RationalObject) ToString(t *Thread) string {
+	return r.value.String()
+}
+
+// Inspect returns the Rational in parentheses
+func (r *RationalObject) Inspect(t *Thread) string {
+	return "(" + r.ToString(t) + ")"
+}
+
+// ToJSON returns the Rational as a JSON string, as it may have no exact decimal form
+func (r *RationalObject) ToJSON(t *Thread) string {
+	return strconv.Quote(r.ToString(t))
+}
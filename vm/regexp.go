@@ -1,7 +1,11 @@
 package vm
 
 import (
+	"fmt"
 	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/robotii/lito/vm/classes"
 	"github.com/robotii/lito/vm/errors"
@@ -16,16 +20,45 @@ type RegexpObject struct {
 	regexp *Regexp
 }
 
+// MatchDataObject is the result of matching a Regexp against a String.
+// Offsets are kept in bytes, as Go gives them, and are returned as character offsets.
+type MatchDataObject struct {
+	BaseObj
+	regexp *RegexpObject
+	input  string
+	// loc holds the start and end of the match and of each group, or -1 for groups which did not match
+	loc []int
+}
+
+var matchDataClass *RClass
+
+// regexps caches compiled patterns, so that literals in loops are only compiled once
+var regexps sync.Map
+
 var regexpClassMethods = []*BuiltinMethodObject{
+	{
+		// Returns the String with any characters which are special in a Regexp escaped
+		Name: "escape",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			s, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+			}
+			return StringObject(regexp.QuoteMeta(string(s)))
+		},
+	},
 	{
 		Name: "new",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
 			if len(args) != 1 {
 				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
 			}
-			arg, ok := args[0].(StringObject)
+			_, ok := args[0].(StringObject)
 			if !ok {
-				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, arg.Class().Name)
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
 			}
 
 			r := initRegexpObject(t.vm, args[0].ToString(t))
@@ -38,6 +71,63 @@ var regexpClassMethods = []*BuiltinMethodObject{
 }
 
 var regexpInstanceMethods = []*BuiltinMethodObject{
+	{
+		// Returns the character offset of the first match in the String, or nil if there is none
+		Name: "=~",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			input, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+			}
+			return receiver.(*RegexpObject).matchIndex(string(input))
+		},
+	},
+	{
+		// Returns an Array of MatchData for each match in the String
+		Name: "find_all",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			input, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+			}
+
+			r := receiver.(*RegexpObject)
+			var matches []Object
+			for _, loc := range r.regexp.FindAllStringSubmatchIndex(string(input), -1) {
+				matches = append(matches, newMatchDataObject(r, string(input), loc))
+			}
+			return InitArrayObject(matches)
+		},
+	},
+	{
+		// Returns the MatchData of the first match in the String, or nil if there is none.
+		// The search starts at the character offset if one is given.
+		Name: "match",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) < 1 || len(args) > 2 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentRange, 1, 2, len(args))
+			}
+			input, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 1, classes.StringClass, args[0].Class().Name)
+			}
+			start := 0
+			if len(args) == 2 {
+				pos, ok := args[1].(IntegerObject)
+				if !ok {
+					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 2, classes.IntegerClass, args[1].Class().Name)
+				}
+				start = byteOffset(string(input), int(pos))
+			}
+			return receiver.(*RegexpObject).match(string(input), start)
+		},
+	},
 	{
 		Name: "match?",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
@@ -57,16 +147,169 @@ var regexpInstanceMethods = []*BuiltinMethodObject{
 		},
 		Primitive: true,
 	},
+	{
+		// Returns an Array of the names of the named groups
+		Name: "names",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return receiver.(*RegexpObject).names()
+		},
+	},
+	{
+		Name: "source",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return StringObject(receiver.(*RegexpObject).regexp.String())
+		},
+		Primitive: true,
+	},
+}
+
+var matchDataInstanceMethods = []*BuiltinMethodObject{
+	{
+		// Returns the text of the group with the given number or name, or nil if it did not match
+		Name: "[]",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			m := receiver.(*MatchDataObject)
+			i, err := m.groupIndex(t, args)
+			if err != nil {
+				return err
+			}
+			if i < 0 {
+				return NIL
+			}
+			return m.group(i)
+		},
+	},
+	{
+		// Returns the character offset of the start of the group, or nil if it did not match
+		Name: "begin",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			m := receiver.(*MatchDataObject)
+			i, err := m.groupIndex(t, args)
+			if err != nil {
+				return err
+			}
+			return m.offset(i, 0)
+		},
+	},
+	{
+		// Returns an Array of the text of each group
+		Name: "captures",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return InitArrayObject(receiver.(*MatchDataObject).groups()[1:])
+		},
+	},
+	{
+		// Returns the character offset of the end of the group, or nil if it did not match
+		Name: "end",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			m := receiver.(*MatchDataObject)
+			i, err := m.groupIndex(t, args)
+			if err != nil {
+				return err
+			}
+			return m.offset(i, 1)
+		},
+	},
+	{
+		Name: "length",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return IntegerObject(len(receiver.(*MatchDataObject).loc) / 2)
+		},
+		Primitive: true,
+	},
+	{
+		// Returns a Hash of the names of the named groups to their text
+		Name: "named_captures",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			m := receiver.(*MatchDataObject)
+			h := newHashObject()
+			for i, name := range m.regexp.regexp.SubexpNames() {
+				if name != "" {
+					h.set(name, m.group(i))
+				}
+			}
+			return h
+		},
+	},
+	{
+		Name: "names",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return receiver.(*MatchDataObject).regexp.names()
+		},
+	},
+	{
+		// Returns an Array of the character offsets of the start and end of the group
+		Name: "offset",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			m := receiver.(*MatchDataObject)
+			i, err := m.groupIndex(t, args)
+			if err != nil {
+				return err
+			}
+			return InitArrayObject([]Object{m.offset(i, 0), m.offset(i, 1)})
+		},
+	},
+	{
+		// Returns the text after the match
+		Name: "post_match",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			m := receiver.(*MatchDataObject)
+			return StringObject(m.input[m.loc[1]:])
+		},
+		Primitive: true,
+	},
+	{
+		// Returns the text before the match
+		Name: "pre_match",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			m := receiver.(*MatchDataObject)
+			return StringObject(m.input[:m.loc[0]])
+		},
+		Primitive: true,
+	},
+	{
+		Name: "regexp",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return receiver.(*MatchDataObject).regexp
+		},
+		Primitive: true,
+	},
+	{
+		Name: "size",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return IntegerObject(len(receiver.(*MatchDataObject).loc) / 2)
+		},
+		Primitive: true,
+	},
+	{
+		// Returns the text of the match
+		Name: "string",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return StringObject(receiver.ToString(t))
+		},
+		Primitive: true,
+	},
+	{
+		// Returns an Array of the text of the match and of each group
+		Name: "to_a",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return InitArrayObject(receiver.(*MatchDataObject).groups())
+		},
+	},
 }
 
 func initRegexpObject(vm *VM, regexpStr string) *RegexpObject {
-	r, err := regexp.Compile(regexpStr)
-	if err != nil {
-		return nil
+	r, ok := regexps.Load(regexpStr)
+	if !ok {
+		re, err := regexp.Compile(regexpStr)
+		if err != nil {
+			return nil
+		}
+		r, _ = regexps.LoadOrStore(regexpStr, re)
 	}
 	return &RegexpObject{
 		BaseObj: BaseObj{class: vm.TopLevelClass(classes.RegexpClass)},
-		regexp:  r,
+		regexp:  r.(*Regexp),
 	}
 }
 
@@ -76,6 +319,135 @@ func initRegexpClass(vm *VM) *RClass {
 		InstanceMethods(regexpInstanceMethods)
 }
 
+func initMatchDataClass(vm *VM) *RClass {
+	matchDataClass = vm.InitClass(classes.MatchDataClass).
+		ClassMethods([]*BuiltinMethodObject{{Name: "new", Fn: NoSuchMethod("new"), Primitive: true}}).
+		InstanceMethods(matchDataInstanceMethods)
+	return matchDataClass
+}
+
+func newMatchDataObject(r *RegexpObject, input string, loc []int) *MatchDataObject {
+	return &MatchDataObject{
+		BaseObj: BaseObj{class: matchDataClass},
+		regexp:  r,
+		input:   input,
+		loc:     loc,
+	}
+}
+
+// match returns the MatchData of the first match at or after the byte offset, or nil
+func (r *RegexpObject) match(input string, start int) Object {
+	loc := r.regexp.FindStringSubmatchIndex(input[start:])
+	if loc == nil {
+		return NIL
+	}
+	for i := range loc {
+		if loc[i] >= 0 {
+			loc[i] += start
+		}
+	}
+	return newMatchDataObject(r, input, loc)
+}
+
+// matchIndex returns the character offset of the first match, or nil
+func (r *RegexpObject) matchIndex(input string) Object {
+	loc := r.regexp.FindStringIndex(input)
+	if loc == nil {
+		return NIL
+	}
+	return IntegerObject(utf8.RuneCountInString(input[:loc[0]]))
+}
+
+// names returns an Array of the names of the named groups
+func (r *RegexpObject) names() *ArrayObject {
+	var names []Object
+	for _, name := range r.regexp.SubexpNames() {
+		if name != "" {
+			names = append(names, StringObject(name))
+		}
+	}
+	return InitArrayObject(names)
+}
+
+// replace returns the input with up to n matches replaced, or all of them if n is negative
+func (r *RegexpObject) replace(input string, n int, replacement func(m *MatchDataObject) string) string {
+	var out strings.Builder
+	last := 0
+	for _, loc := range r.regexp.FindAllStringSubmatchIndex(input, n) {
+		out.WriteString(input[last:loc[0]])
+		out.WriteString(replacement(newMatchDataObject(r, input, loc)))
+		last = loc[1]
+	}
+	out.WriteString(input[last:])
+	return out.String()
+}
+
+// byteOffset returns the byte offset of the character offset, counting from the end if it is negative
+func byteOffset(s string, pos int) int {
+	if pos < 0 {
+		pos += utf8.RuneCountInString(s)
+		if pos < 0 {
+			return 0
+		}
+	}
+	for i := range s {
+		if pos == 0 {
+			return i
+		}
+		pos--
+	}
+	return len(s)
+}
+
+// groupIndex returns the number of the group named by the argument, which is the whole match if
+// there is no argument, or -1 if there is no group with that name
+func (m *MatchDataObject) groupIndex(t *Thread, args []Object) (int, *Error) {
+	if len(args) == 0 {
+		return 0, nil
+	}
+	if len(args) != 1 {
+		return 0, t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case IntegerObject:
+		i := int(arg)
+		if i < 0 || i >= len(m.loc)/2 {
+			return -1, nil
+		}
+		return i, nil
+	case StringObject:
+		return m.regexp.regexp.SubexpIndex(string(arg)), nil
+	default:
+		return 0, t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.IntegerClass+" or "+classes.StringClass, args[0].Class().Name)
+	}
+}
+
+// group returns the text of the group, or nil if it did not match
+func (m *MatchDataObject) group(i int) Object {
+	if m.loc[2*i] < 0 {
+		return NIL
+	}
+	return StringObject(m.input[m.loc[2*i]:m.loc[2*i+1]])
+}
+
+// groups returns the text of the match and of each group
+func (m *MatchDataObject) groups() []Object {
+	groups := make([]Object, len(m.loc)/2)
+	for i := range groups {
+		groups[i] = m.group(i)
+	}
+	return groups
+}
+
+// offset returns the character offset of the start or end of the group, or nil
+func (m *MatchDataObject) offset(i int, end int) Object {
+	if i < 0 || m.loc[2*i] < 0 {
+		return NIL
+	}
+	return IntegerObject(utf8.RuneCountInString(m.input[:m.loc[2*i+end]]))
+}
+
 // Value returns the object
 func (r *RegexpObject) Value() interface{} {
 	return r.regexp.String()
@@ -86,9 +458,9 @@ func (r *RegexpObject) ToString(t *Thread) string {
 	return r.regexp.String()
 }
 
-// Inspect delegates to ToString
+// Inspect returns the pattern as it would be written as a literal
 func (r *RegexpObject) Inspect(t *Thread) string {
-	return r.ToString(t)
+	return "/" + strings.ReplaceAll(r.ToString(t), "/", "\\/") + "/"
 }
 
 // ToJSON just delegates to ToString
@@ -109,3 +481,47 @@ func (r *RegexpObject) EqualTo(with Object) bool {
 
 	return false
 }
+
+// Value returns the text of the match and of each group
+func (m *MatchDataObject) Value() interface{} {
+	return m.groups()
+}
+
+// ToString returns the text of the match
+func (m *MatchDataObject) ToString(t *Thread) string {
+	return m.input[m.loc[0]:m.loc[1]]
+}
+
+// Inspect returns the text of the match and of each group
+func (m *MatchDataObject) Inspect(t *Thread) string {
+	var out strings.Builder
+	out.WriteString("#<MatchData ")
+	out.WriteString(StringObject(m.ToString(t)).Inspect(t))
+	for i, name := range m.regexp.regexp.SubexpNames()[1:] {
+		if name == "" {
+			name = fmt.Sprint(i + 1)
+		}
+		out.WriteString(" " + name + ":" + m.group(i+1).Inspect(t))
+	}
+	out.WriteString(">")
+	return out.String()
+}
+
+// ToJSON returns the text of the match and of each group as a JSON array
+func (m *MatchDataObject) ToJSON(t *Thread) string {
+	return InitArrayObject(m.groups()).ToJSON(t)
+}
+
+// EqualTo returns true if the matches are of the same Regexp at the same place in the same String
+func (m *MatchDataObject) EqualTo(with Object) bool {
+	other, ok := with.(*MatchDataObject)
+	if !ok || m.input != other.input || !m.regexp.EqualTo(other.regexp) || len(m.loc) != len(other.loc) {
+		return false
+	}
+	for i := range m.loc {
+		if m.loc[i] != other.loc[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,344 @@
+package vm
+
+import (
+	"strings"
+
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+// SetObject is a collection of unique objects, kept in insertion order.
+// Objects are the same when they have the same objectKey, so objects of different classes
+// are different, and user objects are the same when their hash methods return the same value.
+type SetObject struct {
+	BaseObj
+	// items holds the objects under their keys
+	items *HashObject
+}
+
+var setClassMethods = []*BuiltinMethodObject{
+	{
+		// Returns a Set of the values of the argument if given, or else an empty Set
+		Name: "new",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) > 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
+			}
+
+			s := newSetObject(t.vm)
+			if len(args) == 1 {
+				s.add(t, enumToArray(t, args[0])...)
+			}
+			return s
+		},
+	},
+}
+
+var setInstanceMethods = []*BuiltinMethodObject{
+	{
+		Name: "&",
+		Fn: setOperator(func(t *Thread, s, other *SetObject) *SetObject {
+			result := newSetObject(t.vm)
+			for _, k := range s.items.keys() {
+				if _, ok := other.items.Pairs[k]; ok {
+					result.items.set(k, s.items.Pairs[k])
+				}
+			}
+			return result
+		}),
+	},
+	{
+		Name: "-",
+		Fn: setOperator(func(t *Thread, s, other *SetObject) *SetObject {
+			result := newSetObject(t.vm)
+			for _, k := range s.items.keys() {
+				if _, ok := other.items.Pairs[k]; !ok {
+					result.items.set(k, s.items.Pairs[k])
+				}
+			}
+			return result
+		}),
+	},
+	{
+		Name: "^",
+		Fn: setOperator(func(t *Thread, s, other *SetObject) *SetObject {
+			result := newSetObject(t.vm)
+			for _, k := range s.items.keys() {
+				if _, ok := other.items.Pairs[k]; !ok {
+					result.items.set(k, s.items.Pairs[k])
+				}
+			}
+			for _, k := range other.items.keys() {
+				if _, ok := s.items.Pairs[k]; !ok {
+					result.items.set(k, other.items.Pairs[k])
+				}
+			}
+			return result
+		}),
+	},
+	{
+		Name: "|",
+		Fn: setOperator(func(t *Thread, s, other *SetObject) *SetObject {
+			result := s.copy().(*SetObject)
+			for _, k := range other.items.keys() {
+				if _, ok := result.items.Pairs[k]; !ok {
+					result.items.set(k, other.items.Pairs[k])
+				}
+			}
+			return result
+		}),
+	},
+	{
+		Name: "<<",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			s := receiver.(*SetObject)
+			s.add(t, args[0])
+			return s
+		},
+	},
+	{
+		Name: "<=",
+		Fn:   setComparison(func(s, other *SetObject) bool { return s.subsetOf(other) }),
+	},
+	{
+		Name: ">=",
+		Fn:   setComparison(func(s, other *SetObject) bool { return other.subsetOf(s) }),
+	},
+	{
+		// Adds the objects which are not already in the Set
+		Name: "add",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) < 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentMore, 1, len(args))
+			}
+			s := receiver.(*SetObject)
+			s.add(t, args...)
+			return s
+		},
+	},
+	{
+		Name: "array",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return InitArrayObject(receiver.(*SetObject).elements())
+		},
+	},
+	{
+		Name: "clear",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			s := receiver.(*SetObject)
+			s.items = newHashObject()
+			return s
+		},
+	},
+	{
+		// Removes the objects, returning the Set
+		Name: "delete",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) < 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentMore, 1, len(args))
+			}
+			s := receiver.(*SetObject)
+			for _, arg := range args {
+				s.items.remove(objectKey(t, arg))
+			}
+			return s
+		},
+	},
+	{
+		Name: "dup",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			newObj := receiver.(*SetObject).copy()
+			t.vm.dupSingletonClass(receiver, newObj)
+			return newObj
+		},
+	},
+	{
+		Name: "each",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			blockFrame := t.GetBlock()
+			if blockFrame == nil {
+				return t.vm.InitErrorObject(t, errors.InternalError, errors.CantYieldWithoutBlockFormat)
+			}
+
+			s := receiver.(*SetObject)
+			for _, el := range s.elements() {
+				t.Yield(blockFrame, el)
+				if blockFrame.IsRemoved() {
+					break
+				}
+			}
+			return s
+		},
+	},
+	{
+		Name: "empty?",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return BooleanObject(receiver.(*SetObject).items.length() == 0)
+		},
+		Primitive: true,
+	},
+	{
+		Name: "include?",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			_, ok := receiver.(*SetObject).items.Pairs[objectKey(t, args[0])]
+			return BooleanObject(ok)
+		},
+	},
+	{
+		Name: "json",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return StringObject(receiver.ToJSON(t))
+		},
+	},
+	{
+		Name: "length",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return IntegerObject(receiver.(*SetObject).items.length())
+		},
+		Primitive: true,
+	},
+	{
+		Name: "string",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return StringObject(receiver.ToString(t))
+		},
+	},
+	{
+		Name: "subset?",
+		Fn:   setComparison(func(s, other *SetObject) bool { return s.subsetOf(other) }),
+	},
+	{
+		Name: "superset?",
+		Fn:   setComparison(func(s, other *SetObject) bool { return other.subsetOf(s) }),
+	},
+}
+
+func initSetClass(vm *VM) *RClass {
+	return vm.InitClass(classes.SetClass).
+		ClassMethods(setClassMethods).
+		InstanceMethods(setInstanceMethods).
+		include(enumerableModule)
+}
+
+func newSetObject(vm *VM) *SetObject {
+	return &SetObject{
+		BaseObj: BaseObj{class: vm.TopLevelClass(classes.SetClass)},
+		items:   newHashObject(),
+	}
+}
+
+// setOperator returns a method combining the Set with another into a new Set
+func setOperator(op func(t *Thread, s, other *SetObject) *SetObject) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 1 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+		}
+		other, ok := args[0].(*SetObject)
+		if !ok {
+			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.SetClass, args[0].Class().Name)
+		}
+		return op(t, receiver.(*SetObject), other)
+	}
+}
+
+// setComparison returns a method comparing the Set with another
+func setComparison(test func(s, other *SetObject) bool) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		if len(args) != 1 {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+		}
+		other, ok := args[0].(*SetObject)
+		if !ok {
+			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.SetClass, args[0].Class().Name)
+		}
+		return BooleanObject(test(receiver.(*SetObject), other))
+	}
+}
+
+// add adds the objects which are not already in the Set
+func (s *SetObject) add(t *Thread, objs ...Object) {
+	for _, obj := range objs {
+		key := objectKey(t, obj)
+		if _, ok := s.items.Pairs[key]; !ok {
+			s.items.set(key, obj)
+		}
+	}
+}
+
+// elements returns the objects in insertion order
+func (s *SetObject) elements() []Object {
+	keys := s.items.keys()
+	elements := make([]Object, len(keys))
+	for i, k := range keys {
+		elements[i] = s.items.Pairs[k]
+	}
+	return elements
+}
+
+// subsetOf returns true if every object in the Set is in the other
+func (s *SetObject) subsetOf(other *SetObject) bool {
+	for k := range s.items.Pairs {
+		if _, ok := other.items.Pairs[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// copy returns a duplicate of the Set
+func (s *SetObject) copy() Object {
+	return &SetObject{
+		BaseObj: BaseObj{class: s.class},
+		items:   s.items.copy().(*HashObject),
+	}
+}
+
+// Value returns the objects in the Set
+func (s *SetObject) Value() interface{} {
+	return s.elements()
+}
+
+// ToString returns the object's name as the string format
+func (s *SetObject) ToString(t *Thread) string {
+	var out strings.Builder
+	out.WriteString("#<Set: {")
+	for i, el := range s.elements() {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(el.Inspect(t))
+	}
+	out.WriteString("}>")
+	return out.String()
+}
+
+// Inspect delegates to ToString
+func (s *SetObject) Inspect(t *Thread) string {
+	return s.ToString(t)
+}
+
+// ToJSON returns the objects as a JSON array
+func (s *SetObject) ToJSON(t *Thread) string {
+	return InitArrayObject(s.elements()).ToJSON(t)
+}
+
+// EqualTo returns true if the other object is a Set with the same objects
+func (s *SetObject) EqualTo(with Object) bool {
+	other, ok := with.(*SetObject)
+	return ok && s.items.length() == other.items.length() && s.subsetOf(other)
+}
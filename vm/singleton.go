@@ -0,0 +1,64 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/robotii/lito/vm/errors"
+)
+
+// singletonObject is implemented by objects which can have methods of their own
+type singletonObject interface {
+	Object
+	singletonClass() *RClass
+	setSingletonClass(*RClass)
+}
+
+func (b *BaseObj) singletonClass() *RClass {
+	return b.singleton
+}
+
+func (b *BaseObj) setSingletonClass(c *RClass) {
+	b.singleton = c
+}
+
+// newSingletonClass returns a class for the methods of one object, whose
+// methods are found before those of the object's class
+func newSingletonClass(vm *VM, class *RClass) *RClass {
+	singleton := createRClass(vm, fmt.Sprintf("#<Class:#<%s>>", class.Name))
+	singleton.superClass = class
+	singleton.pseudoSuperClass = class
+	singleton.scope = class.scope
+	return singleton
+}
+
+// singletonClassOf returns the class holding the receiver's own methods, creating it if needed.
+// The singleton class of a class is its metaclass.
+func (t *Thread) singletonClassOf(receiver Object) (*RClass, *Error) {
+	switch r := receiver.(type) {
+	case *RClass:
+		if r.MetaClass() == nil {
+			r.SetMetaClass(createRClass(t.vm, fmt.Sprintf("#<Class:#<%s:metaclass>>", r.Class().Name)))
+		}
+		return r.MetaClass(), nil
+	case singletonObject:
+		if r.singletonClass() == nil {
+			r.setSingletonClass(newSingletonClass(t.vm, r.Class()))
+		}
+		return r.singletonClass(), nil
+	}
+	return nil, t.vm.InitErrorObject(t, errors.TypeError, errors.CantDefineSingletonMethod, receiver.Class().Name)
+}
+
+// dupSingletonClass gives the copy of an object a copy of the object's singleton class, if it has one
+func (vm *VM) dupSingletonClass(from, to Object) {
+	f, ok := from.(singletonObject)
+	if !ok || f.singletonClass() == nil {
+		return
+	}
+	if to, ok := to.(singletonObject); ok {
+		singleton := newSingletonClass(vm, to.Class())
+		singleton.Methods = f.singletonClass().Methods.copy()
+		singleton.superClass = f.singletonClass().superClass
+		to.setSingletonClass(singleton)
+	}
+}
@@ -2,6 +2,7 @@ package vm
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"unicode"
@@ -46,6 +47,31 @@ var stringClassMethods = []*BuiltinMethodObject{
 			return StringObject(fmt.Sprintf(format, arguments...))
 		},
 	},
+	{
+		// Returns the template with each {name} replaced by the value of that key in the Hash.
+		// A spec can follow the name, as in {name:>10} or {price:08.2f}, giving the fill and
+		// alignment, sign, width, precision and one of the verbs s, d, f, e, x, X, o, b or %.
+		Name: "format",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 2 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 2, len(args))
+			}
+			template, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 1, classes.StringClass, args[0].Class().Name)
+			}
+			values, ok := args[1].(*HashObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 2, classes.HashClass, args[1].Class().Name)
+			}
+
+			result, err := formatTemplate(t, string(template), values)
+			if err != nil {
+				return err
+			}
+			return StringObject(result)
+		},
+	},
 	{
 		Name: "new",
 		Fn:   NoSuchMethod("new"),
@@ -122,6 +148,20 @@ var stringInstanceMethods = []*BuiltinMethodObject{
 			return BooleanObject(string(left) != string(right))
 		},
 	},
+	{
+		// Returns the character offset of the first match of the Regexp, or nil if there is none
+		Name: "=~",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			r, ok := args[0].(*RegexpObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.RegexpClass, args[0].Class().Name)
+			}
+			return r.matchIndex(string(receiver.(StringObject)))
+		},
+	},
 	{
 		Name: "[]",
 		Fn:   stringSlice,
@@ -173,11 +213,81 @@ var stringInstanceMethods = []*BuiltinMethodObject{
 		Name: "capitalise",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
 
+			str := []rune(string(receiver.(StringObject)))
+			if len(str) == 0 {
+				return receiver
+			}
+			return StringObject(string(unicode.ToTitle(str[0])) + strings.ToLower(string(str[1:])))
+		},
+	},
+	{
+		// Compares the Strings ignoring case, returning -1, 0 or 1
+		Name: "casecmp",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			other, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+			}
+			return IntegerObject(strings.Compare(foldCase(string(receiver.(StringObject))), foldCase(string(other))))
+		},
+	},
+	{
+		// Returns true if the Strings are equal ignoring case
+		Name: "casecmp?",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			other, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+			}
+			return BooleanObject(strings.EqualFold(string(receiver.(StringObject)), string(other)))
+		},
+	},
+	{
+		// Pads the String on both sides to the given length, with spaces or the given String
+		Name: "center",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) < 1 || len(args) > 2 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentRange, 1, 2, len(args))
+			}
+			width, ok := args[0].(IntegerObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 1, classes.IntegerClass, args[0].Class().Name)
+			}
+			pad := " "
+			if len(args) == 2 {
+				p, ok := args[1].(StringObject)
+				if !ok || p == "" {
+					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 2, classes.StringClass, args[1].Class().Name)
+				}
+				pad = string(p)
+			}
+
 			str := string(receiver.(StringObject))
-			start := string([]rune(str)[0])
-			rest := string([]rune(str)[1:])
-			result := strings.ToUpper(start) + strings.ToLower(rest)
-			return StringObject(result)
+			total := int(width) - utf8.RuneCountInString(str)
+			if total <= 0 {
+				return receiver
+			}
+			return StringObject(padding(pad, total/2) + str + padding(pad, total-total/2))
+		},
+	},
+	{
+		// Returns an Array of the characters in the String
+		Name: "chars",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			var chars []Object
+			for _, c := range string(receiver.(StringObject)) {
+				chars = append(chars, StringObject(c))
+			}
+			return InitArrayObject(chars)
 		},
 	},
 	{
@@ -306,6 +416,36 @@ var stringInstanceMethods = []*BuiltinMethodObject{
 			return BooleanObject(strings.HasSuffix(str, compareStrValue))
 		},
 	},
+	{
+		Name: "gsub",
+		Fn:   stringReplace(-1),
+	},
+	{
+		// Returns the character offset of the first occurrence of the String or Regexp,
+		// starting from the given offset, or nil if there is none
+		Name: "index",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			str := string(receiver.(StringObject))
+			start, err := stringIndexArgs(t, str, args, 0)
+			if err != nil {
+				return err
+			}
+
+			i := -1
+			switch pattern := args[0].(type) {
+			case StringObject:
+				i = strings.Index(str[start:], string(pattern))
+			case *RegexpObject:
+				if loc := pattern.regexp.FindStringIndex(str[start:]); loc != nil {
+					i = loc[0]
+				}
+			}
+			if i < 0 {
+				return NIL
+			}
+			return IntegerObject(utf8.RuneCountInString(str[:start+i]))
+		},
+	},
 	{
 		Name: "include?",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
@@ -364,6 +504,24 @@ var stringInstanceMethods = []*BuiltinMethodObject{
 		Name: "length",
 		Fn:   stringSize,
 	},
+	{
+		// Returns an Array of the lines in the String, without their line endings
+		Name: "lines",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			str := strings.TrimSuffix(string(receiver.(StringObject)), "\n")
+			if str == "" {
+				return InitArrayObject(nil)
+			}
+			var lines []Object
+			for _, line := range strings.Split(str, "\n") {
+				lines = append(lines, StringObject(strings.TrimSuffix(line, "\r")))
+			}
+			return InitArrayObject(lines)
+		},
+	},
 	{
 		Name: "ljust",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
@@ -409,49 +567,56 @@ var stringInstanceMethods = []*BuiltinMethodObject{
 		},
 	},
 	{
-		Name: "replace",
+		// Returns the MatchData of the first match of the Regexp, or nil if there is none
+		Name: "match",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
-			if len(args) != 2 {
-				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 2, len(args))
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
 			}
-
-			r := args[1]
-			replacement, ok := r.(StringObject)
+			r, ok := args[0].(*RegexpObject)
 			if !ok {
-				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 2, classes.StringClass, args[1].Class().Name)
-			}
-
-			target := string(receiver.(StringObject))
-			switch pattern := args[0].(type) {
-			case StringObject:
-				return StringObject(strings.Replace(target, string(pattern), string(replacement), -1))
-			default:
-				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 1, classes.StringClass+" or "+classes.RegexpClass, args[0].Class().Name)
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.RegexpClass, args[0].Class().Name)
 			}
+			return r.match(string(receiver.(StringObject)), 0)
 		},
 	},
 	{
-		Name: "replace_once",
+		// Splits the String at the first occurrence of the String or Regexp, returning an Array
+		// of the text before it, the separator itself, and the text after it
+		Name: "partition",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
-			if len(args) != 2 {
-				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 2, len(args))
-			}
-
-			r := args[1]
-			replacement, ok := r.(StringObject)
-			if !ok {
-				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 2, classes.StringClass, args[1].Class().Name)
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
 			}
 
-			target := string(receiver.(StringObject))
-			switch pattern := args[0].(type) {
+			str := string(receiver.(StringObject))
+			var loc []int
+			switch sep := args[0].(type) {
 			case StringObject:
-				return StringObject(strings.Replace(target, string(pattern), string(replacement), 1))
+				if i := strings.Index(str, string(sep)); i >= 0 {
+					loc = []int{i, i + len(sep)}
+				}
+			case *RegexpObject:
+				loc = sep.regexp.FindStringIndex(str)
 			default:
-				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 1, classes.StringClass+" or "+classes.RegexpClass, args[0].Class().Name)
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass+" or "+classes.RegexpClass, args[0].Class().Name)
+			}
+			if loc == nil {
+				return InitArrayObject([]Object{StringObject(str), StringObject(""), StringObject("")})
 			}
+			return InitArrayObject([]Object{StringObject(str[:loc[0]]), StringObject(str[loc[0]:loc[1]]), StringObject(str[loc[1]:])})
 		},
 	},
+	{
+		// Replaces every match of the String or Regexp with the replacement, or with the result of
+		// the block, which is given the MatchData. Groups can be used in a replacement as $1 or ${name}.
+		Name: "replace",
+		Fn:   stringReplace(-1),
+	},
+	{
+		Name: "replace_once",
+		Fn:   stringReplace(1),
+	},
 	{
 		Name: "reverse",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
@@ -459,6 +624,39 @@ var stringInstanceMethods = []*BuiltinMethodObject{
 			return StringObject(reverseString(str))
 		},
 	},
+	{
+		// Returns the character offset of the last occurrence of the String or Regexp which starts
+		// at or before the given offset, or nil if there is none
+		Name: "rindex",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			str := string(receiver.(StringObject))
+			end, err := stringIndexArgs(t, str, args, len(str))
+			if err != nil {
+				return err
+			}
+
+			i := -1
+			switch pattern := args[0].(type) {
+			case StringObject:
+				limit := end + len(pattern)
+				if limit > len(str) {
+					limit = len(str)
+				}
+				i = strings.LastIndex(str[:limit], string(pattern))
+			case *RegexpObject:
+				for _, loc := range pattern.regexp.FindAllStringIndex(str, -1) {
+					if loc[0] > end {
+						break
+					}
+					i = loc[0]
+				}
+			}
+			if i < 0 {
+				return NIL
+			}
+			return IntegerObject(utf8.RuneCountInString(str[:i]))
+		},
+	},
 	{
 		Name: "rjust",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
@@ -490,7 +688,7 @@ var stringInstanceMethods = []*BuiltinMethodObject{
 			padStrLength := utf8.RuneCountInString(padStrValue)
 
 			str := string(receiver.(StringObject))
-			if strLengthValue > len(str) {
+			if strLengthValue > utf8.RuneCountInString(str) {
 				origin := str
 				originStrLength := utf8.RuneCountInString(origin)
 				for i := originStrLength; i < strLengthValue; i += padStrLength {
@@ -506,6 +704,60 @@ var stringInstanceMethods = []*BuiltinMethodObject{
 			return StringObject(str)
 		},
 	},
+	{
+		// Returns an Array of each match of the Regexp. If it has groups, each match is an Array of the groups.
+		Name: "scan",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			r, ok := args[0].(*RegexpObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.RegexpClass, args[0].Class().Name)
+			}
+
+			str := string(receiver.(StringObject))
+			var matches []Object
+			for _, loc := range r.regexp.FindAllStringSubmatchIndex(str, -1) {
+				m := newMatchDataObject(r, str, loc)
+				if len(loc) > 2 {
+					matches = append(matches, InitArrayObject(m.groups()[1:]))
+				} else {
+					matches = append(matches, m.group(0))
+				}
+			}
+			return InitArrayObject(matches)
+		},
+	},
+	{
+		// Replaces runs of the same character with one of it. If a String is given, only runs
+		// of the characters in it are replaced.
+		Name: "squeeze",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) > 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
+			}
+			var only []rune
+			if len(args) == 1 {
+				set, ok := args[0].(StringObject)
+				if !ok {
+					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+				}
+				only = expandCharacterSet(string(set))
+			}
+
+			var out strings.Builder
+			last := rune(-1)
+			for _, c := range string(receiver.(StringObject)) {
+				if c == last && (only == nil || runeIndex(only, c) >= 0) {
+					continue
+				}
+				out.WriteRune(c)
+				last = c
+			}
+			return StringObject(out.String())
+		},
+	},
 	{
 		Name: "size",
 		Fn:   stringSize,
@@ -521,13 +773,20 @@ var stringInstanceMethods = []*BuiltinMethodObject{
 				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
 			}
 
-			separator, ok := args[0].(StringObject)
-			if !ok {
-				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
-			}
-
 			str := string(receiver.(StringObject))
-			return StringObjectSplit(t.vm, str, string(separator))
+			switch separator := args[0].(type) {
+			case StringObject:
+				return StringObjectSplit(t.vm, str, string(separator))
+			case *RegexpObject:
+				parts := separator.regexp.Split(str, -1)
+				elements := make([]Object, len(parts))
+				for i, part := range parts {
+					elements[i] = StringObject(part)
+				}
+				return InitArrayObject(elements)
+			default:
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass+" or "+classes.RegexpClass, args[0].Class().Name)
+			}
 		},
 	},
 	{
@@ -569,7 +828,10 @@ var stringInstanceMethods = []*BuiltinMethodObject{
 	{
 		Name: "bytes",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
-			return initGoObject(t.vm, []byte(receiver.(StringObject)))
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return t.vm.InitBytesObject([]byte(receiver.(StringObject)))
 		},
 	},
 	{
@@ -598,17 +860,29 @@ var stringInstanceMethods = []*BuiltinMethodObject{
 		},
 	},
 	{
+		// Parses the string as an Integer. If a base from 2 to 36 is given,
+		// the whole string must be a valid number in that base.
 		Name: "int",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
-			if len(args) != 0 {
-				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			if len(args) > 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
 			}
 
 			str := string(receiver.(StringObject))
-			parsedStr, err := strconv.ParseInt(str, 10, 0)
+			if len(args) == 1 {
+				base, err := numericBase(t, args[0])
+				if err != nil {
+					return err
+				}
+				i, ok := parseBigInteger(strings.TrimSpace(str), base)
+				if !ok {
+					return t.vm.InitErrorObject(t, errors.ArgumentError, errors.InvalidNumericString, str)
+				}
+				return i
+			}
 
-			if err == nil {
-				return IntegerObject(int(parsedStr))
+			if i, ok := parseBigInteger(str, 10); ok {
+				return i
 			}
 
 			var digits string
@@ -622,9 +896,8 @@ var stringInstanceMethods = []*BuiltinMethodObject{
 				}
 			}
 
-			if len(digits) > 0 {
-				parsedStr, _ = strconv.ParseInt(digits, 10, 0)
-				return IntegerObject(int(parsedStr))
+			if i, ok := parseBigInteger(digits, 10); ok {
+				return i
 			}
 
 			return IntegerObject(0)
@@ -645,6 +918,41 @@ var stringInstanceMethods = []*BuiltinMethodObject{
 			return StringObject(receiver.(StringObject).Inspect(t))
 		},
 	},
+	{
+		// Replaces each character in the first String with the character at the same place in the
+		// second, where both can use ranges such as a-z. If the second is shorter its last character
+		// is repeated, and if it is empty the characters are deleted.
+		Name: "tr",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 2 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 2, len(args))
+			}
+			from, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 1, classes.StringClass, args[0].Class().Name)
+			}
+			to, ok := args[1].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 2, classes.StringClass, args[1].Class().Name)
+			}
+
+			fromChars, toChars := expandCharacterSet(string(from)), expandCharacterSet(string(to))
+			var out strings.Builder
+			for _, c := range string(receiver.(StringObject)) {
+				i := runeIndex(fromChars, c)
+				switch {
+				case i < 0:
+					out.WriteRune(c)
+				case len(toChars) == 0:
+				case i < len(toChars):
+					out.WriteRune(toChars[i])
+				default:
+					out.WriteRune(toChars[len(toChars)-1])
+				}
+			}
+			return StringObject(out.String())
+		},
+	},
 	{
 		Name: "upper",
 		Fn: func(receiver Object, t *Thread, args []Object) Object {
@@ -712,6 +1020,111 @@ func (s StringObject) equal(e StringObject) bool {
 	return string(s) == string(e)
 }
 
+// stringIndexArgs checks the arguments of index and rindex, returning the byte offset
+// of the character offset given as the second argument, or def if there is none
+func stringIndexArgs(t *Thread, str string, args []Object, def int) (int, *Error) {
+	if len(args) < 1 || len(args) > 2 {
+		return 0, t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentRange, 1, 2, len(args))
+	}
+	switch args[0].(type) {
+	case StringObject, *RegexpObject:
+	default:
+		return 0, t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 1, classes.StringClass+" or "+classes.RegexpClass, args[0].Class().Name)
+	}
+	if len(args) == 1 {
+		return def, nil
+	}
+	pos, ok := args[1].(IntegerObject)
+	if !ok {
+		return 0, t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 2, classes.IntegerClass, args[1].Class().Name)
+	}
+	return byteOffset(str, int(pos)), nil
+}
+
+// expandCharacterSet returns the characters in the set, where a-z stands for the characters from a to z
+func expandCharacterSet(set string) []rune {
+	r := []rune(set)
+	chars := []rune{}
+	for i := 0; i < len(r); i++ {
+		if i+2 < len(r) && r[i+1] == '-' && r[i] <= r[i+2] {
+			for c := r[i]; c <= r[i+2]; c++ {
+				chars = append(chars, c)
+			}
+			i += 2
+			continue
+		}
+		chars = append(chars, r[i])
+	}
+	return chars
+}
+
+// runeIndex returns the index of the character in the slice, or -1 if it is not there
+func runeIndex(chars []rune, c rune) int {
+	for i, ch := range chars {
+		if ch == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// foldCase returns the String with the case of each character folded, for comparing without case
+func foldCase(s string) string {
+	return strings.ToLower(strings.ToUpper(s))
+}
+
+// padding returns n characters, made by repeating the pad
+func padding(pad string, n int) string {
+	p := []rune(strings.Repeat(pad, n/utf8.RuneCountInString(pad)+1))
+	return string(p[:n])
+}
+
+// stringReplace returns a method replacing up to n matches of a String or Regexp, or all of them if n is negative
+func stringReplace(n int) Method {
+	return func(receiver Object, t *Thread, args []Object) Object {
+		blockFrame := t.GetBlock()
+		argCount := 2
+		if blockFrame != nil {
+			argCount = 1
+		}
+		if len(args) != argCount {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, argCount, len(args))
+		}
+
+		var r *RegexpObject
+		switch pattern := args[0].(type) {
+		case StringObject:
+			if blockFrame == nil {
+				replacement, ok := args[1].(StringObject)
+				if !ok {
+					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 2, classes.StringClass, args[1].Class().Name)
+				}
+				return StringObject(strings.Replace(string(receiver.(StringObject)), string(pattern), string(replacement), n))
+			}
+			r = initRegexpObject(t.vm, regexp.QuoteMeta(string(pattern)))
+		case *RegexpObject:
+			r = pattern
+		default:
+			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 1, classes.StringClass+" or "+classes.RegexpClass, args[0].Class().Name)
+		}
+
+		target := string(receiver.(StringObject))
+		if blockFrame != nil {
+			return StringObject(r.replace(target, n, func(m *MatchDataObject) string {
+				return t.Yield(blockFrame, m).ToString(t)
+			}))
+		}
+
+		replacement, ok := args[1].(StringObject)
+		if !ok {
+			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 2, classes.StringClass, args[1].Class().Name)
+		}
+		return StringObject(r.replace(target, n, func(m *MatchDataObject) string {
+			return string(r.regexp.ExpandString(nil, string(replacement), m.input, m.loc))
+		}))
+	}
+}
+
 // StringObjectSplit returns an ArrayObject with the split strings
 func StringObjectSplit(vm *VM, s string, sep string) *ArrayObject {
 	arr := strings.Split(s, sep)
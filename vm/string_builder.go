@@ -0,0 +1,126 @@
+package vm
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+// StringBuilderObject builds up a String from pieces, without copying what has
+// been written so far each time a piece is added.
+type StringBuilderObject struct {
+	BaseObj
+	builder strings.Builder
+	// length is the number of characters written
+	length int
+}
+
+var stringBuilderClassMethods = []*BuiltinMethodObject{
+	{
+		// Returns a new StringBuilder, holding the strings of any arguments
+		Name: "new",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			sb := &StringBuilderObject{BaseObj: BaseObj{class: t.vm.TopLevelClass(classes.StringBuilderClass)}}
+			sb.write(t, args)
+			return sb
+		},
+	},
+}
+
+var stringBuilderInstanceMethods = []*BuiltinMethodObject{
+	{
+		// Appends the string of the object, returning the StringBuilder
+		Name: "<<",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			sb := receiver.(*StringBuilderObject)
+			sb.write(t, args)
+			return sb
+		},
+	},
+	{
+		Name: "clear",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			sb := receiver.(*StringBuilderObject)
+			sb.builder.Reset()
+			sb.length = 0
+			return sb
+		},
+	},
+	{
+		Name: "empty?",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return BooleanObject(receiver.(*StringBuilderObject).length == 0)
+		},
+		Primitive: true,
+	},
+	{
+		// Returns the number of characters written
+		Name: "length",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return IntegerObject(receiver.(*StringBuilderObject).length)
+		},
+		Primitive: true,
+	},
+	{
+		Name: "string",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return StringObject(receiver.ToString(t))
+		},
+		Primitive: true,
+	},
+	{
+		// Appends the strings of the objects, returning the number of characters written
+		Name: "write",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return IntegerObject(receiver.(*StringBuilderObject).write(t, args))
+		},
+	},
+}
+
+func initStringBuilderClass(vm *VM) *RClass {
+	return vm.InitClass(classes.StringBuilderClass).
+		ClassMethods(stringBuilderClassMethods).
+		InstanceMethods(stringBuilderInstanceMethods)
+}
+
+// write appends the strings of the objects, returning the number of characters written
+func (sb *StringBuilderObject) write(t *Thread, objs []Object) int {
+	n := 0
+	for _, obj := range objs {
+		s := obj.ToString(t)
+		sb.builder.WriteString(s)
+		n += utf8.RuneCountInString(s)
+	}
+	sb.length += n
+	return n
+}
+
+// Value returns the String built so far
+func (sb *StringBuilderObject) Value() interface{} {
+	return sb.builder.String()
+}
+
+// ToString returns the String built so far
+func (sb *StringBuilderObject) ToString(t *Thread) string {
+	return sb.builder.String()
+}
+
+// Inspect returns the String built so far, with the class name
+func (sb *StringBuilderObject) Inspect(t *Thread) string {
+	return "#<StringBuilder " + StringObject(sb.builder.String()).Inspect(t) + ">"
+}
+
+// ToJSON returns the String built so far as a JSON string
+func (sb *StringBuilderObject) ToJSON(t *Thread) string {
+	return StringObject(sb.builder.String()).ToJSON(t)
+}
+
+// EqualTo returns true if the objects are the same StringBuilder
+func (sb *StringBuilderObject) EqualTo(with Object) bool {
+	return sb == with
+}
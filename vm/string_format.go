@@ -0,0 +1,201 @@
+package vm
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+// formatSpec is a parsed placeholder spec, written [[fill]align][+][0][width][.precision][type],
+// as in {price:>10.2f}
+type formatSpec struct {
+	fill      rune
+	align     rune
+	sign      bool
+	zero      bool
+	width     int
+	precision int
+	verb      rune
+}
+
+// formatTemplate replaces each {name} or {name:spec} in the template with the formatted value
+// of that key in the Hash. Braces are written as {{ and }}.
+func formatTemplate(t *Thread, template string, values *HashObject) (string, *Error) {
+	var out strings.Builder
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		switch {
+		case c == '{' && strings.HasPrefix(template[i:], "{{"), c == '}' && strings.HasPrefix(template[i:], "}}"):
+			out.WriteByte(c)
+			i++
+		case c == '{':
+			end := strings.IndexByte(template[i:], '}')
+			if end < 0 {
+				return "", t.vm.InitErrorObject(t, errors.ArgumentError, "Unclosed placeholder in format: %s", template[i:])
+			}
+			placeholder := template[i+1 : i+end]
+			i += end
+
+			name, specStr, _ := strings.Cut(placeholder, ":")
+			value, ok := values.Pairs[name]
+			if !ok && values.Default == nil && values.defaultBlock == nil {
+				return "", t.vm.InitErrorObject(t, errors.ArgumentError, "Key not found: %s", name)
+			} else if !ok {
+				value = values.get(t, name)
+			}
+
+			spec, ok := parseFormatSpec(specStr)
+			if !ok {
+				return "", t.vm.InitErrorObject(t, errors.ArgumentError, "Invalid format spec: %s", specStr)
+			}
+			s, err := spec.format(t, value)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(s)
+		case c == '}':
+			return "", t.vm.InitErrorObject(t, errors.ArgumentError, "Unmatched '}' in format: %s", template)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String(), nil
+}
+
+func parseFormatSpec(spec string) (formatSpec, bool) {
+	f := formatSpec{fill: ' ', precision: -1}
+	r := []rune(spec)
+	isAlign := func(c rune) bool { return c == '<' || c == '>' || c == '^' }
+
+	i := 0
+	if len(r) > 1 && isAlign(r[1]) {
+		f.fill, f.align = r[0], r[1]
+		i = 2
+	} else if len(r) > 0 && isAlign(r[0]) {
+		f.align = r[0]
+		i = 1
+	}
+	if i < len(r) && r[i] == '+' {
+		f.sign = true
+		i++
+	}
+	if i < len(r) && r[i] == '0' {
+		f.zero = true
+		i++
+	}
+	start := i
+	for i < len(r) && '0' <= r[i] && r[i] <= '9' {
+		i++
+	}
+	if i > start {
+		f.width, _ = strconv.Atoi(string(r[start:i]))
+	}
+	if i < len(r) && r[i] == '.' {
+		i++
+		start = i
+		for i < len(r) && '0' <= r[i] && r[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return f, false
+		}
+		f.precision, _ = strconv.Atoi(string(r[start:i]))
+	}
+	if i < len(r) {
+		f.verb = r[i]
+		i++
+		if !strings.ContainsRune("sdfexXob%", f.verb) {
+			return f, false
+		}
+	}
+	return f, i == len(r)
+}
+
+// format returns the value formatted and padded as the spec describes
+func (f formatSpec) format(t *Thread, value Object) (string, *Error) {
+	var s string
+	numeric := false
+
+	switch f.verb {
+	case 'd', 'x', 'X', 'o', 'b':
+		i, ok := value.(IntegerObject)
+		if !ok {
+			return "", t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.IntegerClass, value.Class().Name)
+		}
+		base := map[rune]int{'d': 10, 'x': 16, 'X': 16, 'o': 8, 'b': 2}[f.verb]
+		s = strconv.FormatInt(int64(i), base)
+		if f.verb == 'X' {
+			s = strings.ToUpper(s)
+		}
+		numeric = true
+	case 'f', 'e', '%':
+		n, ok := value.(Numeric)
+		if !ok {
+			return "", t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, "Numeric", value.Class().Name)
+		}
+		precision := f.precision
+		if precision < 0 {
+			precision = 6
+		}
+		switch f.verb {
+		case '%':
+			s = strconv.FormatFloat(n.floatValue()*100, 'f', precision, 64) + "%"
+		default:
+			s = strconv.FormatFloat(n.floatValue(), byte(f.verb), precision, 64)
+		}
+		numeric = true
+	default:
+		switch v := value.(type) {
+		case FloatObject:
+			if f.precision >= 0 {
+				s = strconv.FormatFloat(float64(v), 'f', f.precision, 64)
+			} else {
+				s = v.ToString(t)
+			}
+			numeric = true
+		case Numeric:
+			s = value.ToString(t)
+			numeric = true
+		default:
+			s = v.ToString(t)
+			if f.precision >= 0 && utf8.RuneCountInString(s) > f.precision {
+				s = string([]rune(s)[:f.precision])
+			}
+		}
+	}
+
+	sign := ""
+	if numeric && strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	} else if numeric && f.sign {
+		sign = "+"
+	}
+
+	pad := f.width - utf8.RuneCountInString(sign+s)
+	if pad <= 0 {
+		return sign + s, nil
+	}
+	if f.zero && numeric && f.align == 0 {
+		return sign + strings.Repeat("0", pad) + s, nil
+	}
+
+	align := f.align
+	if align == 0 {
+		align = '<'
+		if numeric {
+			align = '>'
+		}
+	}
+	fill := string(f.fill)
+	switch align {
+	case '>':
+		return strings.Repeat(fill, pad) + sign + s, nil
+	case '^':
+		return strings.Repeat(fill, pad/2) + sign + s + strings.Repeat(fill, pad-pad/2), nil
+	default:
+		return sign + s + strings.Repeat(fill, pad), nil
+	}
+}
@@ -0,0 +1,269 @@
+package vm
+
+import (
+	"hash/fnv"
+	"strings"
+
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+// StructObject is an instance of a class made by Struct new.
+// Its members are kept in instance variables, so the class's accessors and
+// any methods defined on it see them in the usual way.
+type StructObject struct {
+	BaseObj
+}
+
+var structClass *RClass
+
+var structClassMethods = []*BuiltinMethodObject{
+	{
+		// On Struct, returns a new class with the given members, evaluating the block in it.
+		// On a class made by Struct, returns a new instance, taking the members by position or keyword.
+		Name: "new",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			class := receiver.(*RClass)
+			if class == structClass {
+				return defineStruct(t, args)
+			}
+			return newStructObject(t, class, args)
+		},
+	},
+	{
+		Name: "members",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			members := structMembers(receiver.(*RClass))
+			names := make([]Object, len(members))
+			for i, m := range members {
+				names[i] = StringObject(m)
+			}
+			return InitArrayObject(names)
+		},
+	},
+}
+
+var structInstanceMethods = []*BuiltinMethodObject{
+	{
+		// Returns the values of the members as an Array, for destructuring
+		Name: "deconstruct",
+		Fn:   structToArray,
+	},
+	{
+		// Returns an Integer which is the same for equal structs
+		Name: "hash",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			h := fnv.New64a()
+			h.Write([]byte(receiver.Class().Name))
+			for _, v := range receiver.(*StructObject).values() {
+				h.Write([]byte{0})
+				h.Write([]byte(objectKey(t, v)))
+			}
+			return IntegerObject(int(h.Sum64() >> 1))
+		},
+	},
+	{
+		Name: "inspect",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return StringObject(receiver.Inspect(t))
+		},
+	},
+	{
+		Name: "json",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return StringObject(receiver.ToJSON(t))
+		},
+	},
+	{
+		Name: "members",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			members := structMembers(receiver.Class())
+			names := make([]Object, len(members))
+			for i, m := range members {
+				names[i] = StringObject(m)
+			}
+			return InitArrayObject(names)
+		},
+	},
+	{
+		Name: "string",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return StringObject(receiver.ToString(t))
+		},
+	},
+	{
+		Name: "to_a",
+		Fn:   structToArray,
+	},
+	{
+		// Returns a Hash of the members to their values
+		Name: "to_h",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 0 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+			}
+			return receiver.(*StructObject).toHash()
+		},
+	},
+}
+
+func initStructClass(vm *VM) *RClass {
+	structClass = vm.InitClass(classes.StructClass).
+		ClassMethods(structClassMethods).
+		InstanceMethods(structInstanceMethods)
+	return structClass
+}
+
+// defineStruct returns a new class inheriting from Struct, with accessors for the members
+func defineStruct(t *Thread, args []Object) Object {
+	if len(args) < 1 {
+		return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentMore, 1, len(args))
+	}
+
+	members := make([]string, len(args))
+	for i, arg := range args {
+		name, ok := arg.(StringObject)
+		if !ok {
+			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, arg.Class().Name)
+		}
+		for _, m := range members[:i] {
+			if m == string(name) {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, "Duplicate member: %s", name)
+			}
+		}
+		members[i] = string(name)
+	}
+
+	// The class is named when it is assigned to a constant
+	class := t.vm.InitClass("").inherits(structClass)
+	class.members = members
+	class.addProperty(args)
+
+	if blockFrame := t.GetBlock(); blockFrame != nil && !blockFrame.IsEmpty() {
+		// Evaluate the block with self set to the class, so that methods are defined on it
+		cf := newNormalCallFrame(blockFrame.instructionSet, blockFrame.instructionSet.Filename, blockFrame.instructionSet.SourceMap[0])
+		cf.ep = blockFrame.ep
+		cf.self = class
+		cf.isBlock = true
+		t.Yield(cf, class)
+	}
+	return class
+}
+
+// newStructObject returns an instance of the class. Members which are not given are nil.
+func newStructObject(t *Thread, class *RClass, args []Object) Object {
+	members := structMembers(class)
+	keywords, positional := t.keywordArguments(args)
+	if len(positional) > len(members) {
+		return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, len(members), len(positional))
+	}
+
+	s := &StructObject{BaseObj: BaseObj{class: class}}
+	for i, m := range members {
+		var value Object = NIL
+		if i < len(positional) {
+			value = positional[i]
+		}
+		if v, ok := keywords[m]; ok {
+			value = v
+			delete(keywords, m)
+		}
+		s.SetVariable("@"+m, value)
+	}
+	for name := range keywords {
+		return t.vm.InitErrorObject(t, errors.ArgumentError, "Unknown member: %s", name)
+	}
+	return s
+}
+
+// structMembers returns the members of a class made by Struct, or of the class it inherits from
+func structMembers(class *RClass) []string {
+	for c := class; c != nil && c.Name != classes.ObjectClass; c = c.superClass {
+		if c.members != nil {
+			return c.members
+		}
+	}
+	return nil
+}
+
+func structToArray(receiver Object, t *Thread, args []Object) Object {
+	if len(args) != 0 {
+		return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 0, len(args))
+	}
+	return InitArrayObject(receiver.(*StructObject).values())
+}
+
+// values returns the values of the members in order
+func (s *StructObject) values() []Object {
+	members := structMembers(s.class)
+	values := make([]Object, len(members))
+	for i, m := range members {
+		values[i], _ = s.GetVariable("@" + m)
+	}
+	return values
+}
+
+// toHash returns a Hash of the members to their values
+func (s *StructObject) toHash() *HashObject {
+	h := newHashObject()
+	for i, v := range s.values() {
+		h.set(structMembers(s.class)[i], v)
+	}
+	return h
+}
+
+// Value returns the values of the members
+func (s *StructObject) Value() interface{} {
+	return s.values()
+}
+
+// ToString uses the class's string method if it defines one, otherwise it
+// lists the members and their values
+func (s *StructObject) ToString(t *Thread) string {
+	if t != nil {
+		if result, ok := callHook(t, s, "string"); ok {
+			return result.ToString(t)
+		}
+	}
+	return s.Inspect(t)
+}
+
+// Inspect returns the class name with the members and their values
+func (s *StructObject) Inspect(t *Thread) string {
+	var out strings.Builder
+	out.WriteString("#<struct ")
+	if s.class.Name != "" {
+		out.WriteString(s.class.Name + " ")
+	}
+	for i, v := range s.values() {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(structMembers(s.class)[i] + "=" + v.Inspect(t))
+	}
+	out.WriteString(">")
+	return out.String()
+}
+
+// ToJSON returns a JSON object of the members and their values
+func (s *StructObject) ToJSON(t *Thread) string {
+	return s.toHash().ToJSON(t)
+}
+
+// EqualTo returns true if the object is of the same class, with equal values
+func (s *StructObject) EqualTo(with Object) bool {
+	other, ok := with.(*StructObject)
+	if !ok || other.class != s.class {
+		return false
+	}
+	values := other.values()
+	for i, v := range s.values() {
+		if !v.EqualTo(values[i]) {
+			return false
+		}
+	}
+	return true
+}
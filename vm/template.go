@@ -0,0 +1,730 @@
+package vm
+
+import (
+	"container/list"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/robotii/lito/compiler"
+	"github.com/robotii/lito/compiler/bytecode"
+	"github.com/robotii/lito/compiler/parser"
+	"github.com/robotii/lito/compiler/token"
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+// TemplateObject is a parsed template. Templates are compiled into a Lito block,
+// once for each set of Hash keys they are rendered with.
+type TemplateObject struct {
+	BaseObj
+	name   string
+	source string
+	escape bool
+	nodes  []templateNode
+	// texts are the literal pieces of the template, written by index
+	texts []string
+
+	mutex sync.Mutex
+	// compiled holds the blocks compiled for the most recently used sets of locals
+	compiled *lruCache
+}
+
+// templateNode is a piece of the template translated into a Lito statement,
+// which is kept on the line of the template it came from
+type templateNode struct {
+	line int
+	code string
+}
+
+// templateWriter collects the output of a template while it is rendered
+type templateWriter struct {
+	BaseObj
+	tpl    *TemplateObject
+	out    *strings.Builder
+	self   Object
+	locals *HashObject
+	depth  int
+}
+
+const (
+	// maxTemplateDepth limits how deeply partials can include each other
+	maxTemplateDepth = 64
+	// maxCachedTemplates limits how many parsed templates are cached
+	maxCachedTemplates = 256
+	// maxCompiledTemplates limits how many sets of locals a template keeps compiled blocks for
+	maxCompiledTemplates = 16
+)
+
+var (
+	templateClass       *RClass
+	templateWriterClass *RClass
+
+	// templates caches the most recently used parsed templates by name, options and source
+	templates = newLRUCache(maxCachedTemplates)
+	// templatePartials holds the partials registered by name
+	templatePartials sync.Map
+
+	templateFor   = regexp.MustCompile(`^for\s+([a-z_]\w*)(?:\s*,\s*([a-z_]\w*))?\s+in\s+(.+)$`)
+	templateLocal = regexp.MustCompile(`^[a-z_]\w*$`)
+	// compileErrorLine finds the line in a compile error
+	compileErrorLine = regexp.MustCompile(`\s*Line: (\d+)`)
+)
+
+var templateClassMethods = []*BuiltinMethodObject{
+	{
+		// Escapes the HTML special characters in the string of the object
+		Name: "escape",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			return StringObject(html.EscapeString(args[0].ToString(t)))
+		},
+	},
+	{
+		// Reads and parses the template in the file at the path.
+		// Takes the keyword argument escape, as new does.
+		Name: "load",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			options, args := t.keywordArguments(args)
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			path, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+			}
+			return loadTemplate(t, string(path), options["escape"] != nil && options["escape"].IsTruthy())
+		},
+	},
+	{
+		// Parses the template source. Takes the keyword arguments escape, which
+		// HTML escapes the values output by {{ }}, and name, which is used in errors.
+		// Templates with the same source and options are only parsed once.
+		Name: "new",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			options, args := t.keywordArguments(args)
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+			source, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+			}
+			name := "template"
+			if n, ok := options["name"]; ok {
+				name = n.ToString(t)
+			}
+			return newTemplate(t, name, string(source), options["escape"] != nil && options["escape"].IsTruthy())
+		},
+	},
+	{
+		// Registers a partial, given as a Template or as source, under the name
+		// used to include it
+		Name: "register",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 2 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 2, len(args))
+			}
+			name, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 1, classes.StringClass, args[0].Class().Name)
+			}
+			switch partial := args[1].(type) {
+			case *TemplateObject, StringObject:
+				templatePartials.Store(string(name), partial)
+			default:
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 2, classes.StringClass, args[1].Class().Name)
+			}
+			return args[1]
+		},
+	},
+}
+
+var templateInstanceMethods = []*BuiltinMethodObject{
+	{
+		Name: "name",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return StringObject(receiver.(*TemplateObject).name)
+		},
+	},
+	{
+		// Renders the template. The keys of a Hash binding are the template's local
+		// variables, while any other object is self in the template.
+		Name: "render",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) > 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
+			}
+			var binding Object = NIL
+			if len(args) == 1 {
+				binding = args[0]
+			}
+			self, locals := templateBinding(t, binding)
+
+			var out strings.Builder
+			if err := receiver.(*TemplateObject).render(t, &out, self, locals, 0); err != nil {
+				return err
+			}
+			return StringObject(out.String())
+		},
+	},
+	{
+		Name: "source",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			return StringObject(receiver.(*TemplateObject).source)
+		},
+	},
+}
+
+var templateWriterInstanceMethods = []*BuiltinMethodObject{
+	{
+		// Renders a partial, given the loop variables in scope, the name of the
+		// partial and an optional Hash, which are merged into the current locals
+		Name: "include",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) < 2 || len(args) > 3 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentRange, 2, 3, len(args))
+			}
+			w := receiver.(*templateWriter)
+			if w.depth >= maxTemplateDepth {
+				return t.vm.InitErrorObject(t, errors.TemplateError, "Partials nested too deeply in %s", w.tpl.name)
+			}
+
+			partial, err := w.tpl.partial(t, args[1].ToString(t))
+			if err != nil {
+				return err
+			}
+			scopes := []*HashObject{w.locals, args[0].(*HashObject)}
+			if len(args) == 3 {
+				h, ok := args[2].(*HashObject)
+				if !ok {
+					return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormatNum, 2, classes.HashClass, args[2].Class().Name)
+				}
+				scopes = append(scopes, h)
+			}
+			locals := newHashObject()
+			for _, h := range scopes {
+				if h != nil {
+					for _, k := range h.keys() {
+						locals.set(k, h.Pairs[k])
+					}
+				}
+			}
+
+			if err := partial.render(t, w.out, w.self, locals, w.depth+1); err != nil {
+				return err
+			}
+			return NIL
+		},
+	},
+	{
+		Name: "raw",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			receiver.(*templateWriter).out.WriteString(args[0].ToString(t))
+			return NIL
+		},
+	},
+	{
+		Name: "text",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			w := receiver.(*templateWriter)
+			w.out.WriteString(w.tpl.texts[args[0].(IntegerObject)])
+			return NIL
+		},
+	},
+	{
+		// Writes the string of the object, escaped if the template escapes HTML
+		Name: "write",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			w := receiver.(*templateWriter)
+			s := args[0].ToString(t)
+			if w.tpl.escape {
+				s = html.EscapeString(s)
+			}
+			w.out.WriteString(s)
+			return NIL
+		},
+	},
+}
+
+// templateErrorInstanceMethods give access to where in a template an error is.
+// Errors raised while rendering a template record it as well as TemplateErrors do.
+var templateErrorInstanceMethods = []*BuiltinMethodObject{
+	generateGetMethod("template"),
+	generateGetMethod("line"),
+}
+
+func initTemplateClass(vm *VM) {
+	templateClass = vm.InitClass("Template").
+		ClassMethods(templateClassMethods).
+		InstanceMethods(templateInstanceMethods)
+	templateWriterClass = vm.InitClass("TemplateWriter").
+		InstanceMethods(templateWriterInstanceMethods)
+	vm.TopLevelClass(classes.ObjectClass).SetClassConstant(templateClass)
+	vm.errorClass.InstanceMethods(templateErrorInstanceMethods)
+}
+
+// initTemplateError returns a TemplateError recording the template and line it occurred on
+func initTemplateError(t *Thread, name string, line int, message string) *Error {
+	e := t.vm.InitErrorObject(t, errors.TemplateError, "%s:%d: %s", name, line, message)
+	e.SetVariable("@template", StringObject(name))
+	e.SetVariable("@line", IntegerObject(line))
+	return e
+}
+
+// newTemplate returns the parsed template, from the cache if it has been parsed before
+func newTemplate(t *Thread, name, source string, escape bool) Object {
+	key := name + "\x00" + strconv.FormatBool(escape) + "\x00" + source
+	if tpl, ok := templates.get(key); ok {
+		return tpl.(*TemplateObject)
+	}
+
+	tpl := &TemplateObject{
+		BaseObj:  BaseObj{class: templateClass},
+		name:     name,
+		source:   source,
+		escape:   escape,
+		compiled: newLRUCache(maxCompiledTemplates),
+	}
+	if line, err := tpl.parse(); err != "" {
+		return initTemplateError(t, name, line, err)
+	}
+	// Compile it without locals, so that syntax errors are raised here
+	if _, err := tpl.compile(t, nil); err != nil {
+		return err
+	}
+	templates.put(key, tpl)
+	return tpl
+}
+
+// loadTemplate reads and parses the template in the file
+func loadTemplate(t *Thread, path string, escape bool) Object {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return t.vm.InitErrorObject(t, errors.IOError, "Can't read template %s: %s", path, err.Error())
+	}
+	return newTemplate(t, path, string(source), escape)
+}
+
+// templateBinding returns self and the local variables for rendering with the binding
+func templateBinding(t *Thread, binding Object) (Object, *HashObject) {
+	switch b := binding.(type) {
+	case *HashObject:
+		return t.vm.mainObj, b
+	case *NilObject:
+		return t.vm.mainObj, nil
+	default:
+		return binding, nil
+	}
+}
+
+// parse splits the source into text and tags, translating each into a Lito statement.
+// It returns the line and message of the first error.
+func (tpl *TemplateObject) parse() (int, string) {
+	type block struct {
+		kind   string
+		line   int
+		isElse bool
+		// vars are the loop variables of a for
+		vars []string
+	}
+	var open []block
+	src := tpl.source
+	line := 1
+	trim := false
+
+	for len(src) > 0 {
+		start := len(src)
+		for _, delim := range []string{"{{", "{%", "{#"} {
+			if i := strings.Index(src, delim); i >= 0 && i < start {
+				start = i
+			}
+		}
+
+		text := src[:start]
+		if trim {
+			text = strings.TrimLeft(text, " \t\r\n")
+		}
+		if start < len(src) && strings.HasPrefix(src[start+2:], "-") {
+			text = strings.TrimRight(text, " \t\r\n")
+		}
+		if text != "" {
+			tpl.nodes = append(tpl.nodes, templateNode{line, "__out.text(" + strconv.Itoa(len(tpl.texts)) + ")"})
+			tpl.texts = append(tpl.texts, text)
+		}
+		line += strings.Count(src[:start], "\n")
+		if start == len(src) {
+			break
+		}
+		src = src[start:]
+
+		open2, close := src[:2], map[byte]string{'{': "}}", '%': "%}", '#': "#}"}[src[1]]
+		raw := strings.HasPrefix(src, "{{{")
+		if raw {
+			open2, close = "{{{", "}}}"
+		}
+		end := strings.Index(src[len(open2):], close)
+		if end < 0 {
+			return line, "Unclosed " + open2
+		}
+		tag := src[len(open2) : len(open2)+end]
+		tagLine := line
+		line += strings.Count(src[:len(open2)+end+len(close)], "\n")
+		src = src[len(open2)+end+len(close):]
+
+		tag = strings.TrimPrefix(tag, "-")
+		trim = strings.HasSuffix(tag, "-")
+		tag = strings.TrimSuffix(tag, "-")
+		// Each tag is compiled onto a single line
+		tag = strings.TrimSpace(strings.NewReplacer("\r\n", " ", "\n", " ").Replace(tag))
+
+		var code string
+		switch {
+		case open2 == "{#":
+			continue
+		case tag == "":
+			return tagLine, "Empty " + open2 + " " + close
+		case raw:
+			code = "__out.raw((" + tag + "))"
+		case open2 == "{{":
+			code = "__out.write((" + tag + "))"
+		default:
+			keyword, rest, _ := strings.Cut(tag, " ")
+			rest = strings.TrimSpace(rest)
+			switch keyword {
+			case "if":
+				if rest == "" {
+					return tagLine, "Missing condition in {% if %}"
+				}
+				open = append(open, block{kind: "if", line: tagLine})
+				code = "if " + rest + " {"
+			case "elsif", "else":
+				if len(open) == 0 || open[len(open)-1].kind != "if" || open[len(open)-1].isElse {
+					return tagLine, "{% " + keyword + " %} without {% if %}"
+				}
+				if keyword == "elsif" {
+					if rest == "" {
+						return tagLine, "Missing condition in {% elsif %}"
+					}
+					code = "} elsif " + rest + " {"
+				} else {
+					open[len(open)-1].isElse = true
+					code = "} else {"
+				}
+			case "for":
+				m := templateFor.FindStringSubmatch(tag)
+				if m == nil {
+					return tagLine, "Invalid {% for %}, expected {% for x in xs %}: " + tag
+				}
+				vars := []string{m[1]}
+				if m[2] != "" {
+					vars = append(vars, m[2])
+				}
+				params := strings.Join(vars, ", ")
+				open = append(open, block{kind: "for", line: tagLine, vars: vars})
+				code = "(" + m[3] + ").each {|" + params + "|"
+			case "end", "endif", "endfor":
+				if len(open) == 0 {
+					return tagLine, "{% " + keyword + " %} without an open tag"
+				}
+				if kind := open[len(open)-1].kind; keyword != "end" && keyword != "end"+kind {
+					return tagLine, fmt.Sprintf("{%% %s %%} closes {%% %s %%} from line %d", keyword, kind, open[len(open)-1].line)
+				}
+				open = open[:len(open)-1]
+				code = "}"
+			case "include":
+				if rest == "" {
+					return tagLine, "Missing partial name in {% include %}"
+				}
+				// Partials see the loop variables, as well as the locals of the binding
+				var vars []string
+				for _, b := range open {
+					for _, v := range b.vars {
+						vars = append(vars, v+": "+v)
+					}
+				}
+				code = "__out.include({ " + strings.Join(vars, ", ") + " }, " + rest + ")"
+			default:
+				return tagLine, "Unknown tag {% " + keyword + " %}"
+			}
+		}
+		tpl.nodes = append(tpl.nodes, templateNode{tagLine, code})
+	}
+
+	if len(open) > 0 {
+		b := open[len(open)-1]
+		return b.line, "Unclosed {% " + b.kind + " %}"
+	}
+	return 0, ""
+}
+
+// code returns the Lito source of the template as a block taking the locals and
+// the writer, with a statement on each line, and the template line of each line
+func (tpl *TemplateObject) code(locals []string) (string, []int) {
+	var out strings.Builder
+	out.WriteString("__template {|__binding, __out|")
+	lines := []int{1}
+	for _, name := range locals {
+		out.WriteString("\n" + name + " = __binding[" + strconv.Quote(name) + "]")
+		lines = append(lines, 1)
+	}
+	for _, node := range tpl.nodes {
+		out.WriteString("\n" + node.code)
+		lines = append(lines, node.line)
+	}
+	out.WriteString("\n}")
+	lines = append(lines, lines[len(lines)-1])
+	return out.String(), lines
+}
+
+// compile returns the instructions of the template block for the locals, with
+// the source lines of the instructions set to the lines of the template
+func (tpl *TemplateObject) compile(t *Thread, locals []string) (*bytecode.InstructionSet, *Error) {
+	key := strings.Join(locals, ",")
+	tpl.mutex.Lock()
+	defer tpl.mutex.Unlock()
+	if is, ok := tpl.compiled.get(key); ok {
+		return is.(*bytecode.InstructionSet), nil
+	}
+
+	code, lines := tpl.code(locals)
+	templateLine := func(line int) int {
+		if line < 1 || line > len(lines) {
+			return lines[len(lines)-1]
+		}
+		return lines[line-1]
+	}
+
+	sets, err := compiler.CompileToInstructions(code, parser.NormalMode)
+	if err != nil {
+		if m := compileErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			msg := strings.TrimSpace(strings.Replace(err.Error(), m[0], "", 1))
+			return nil, initTemplateError(t, tpl.name, templateLine(line+1), msg)
+		}
+		return nil, t.vm.InitErrorObject(t, errors.TemplateError, "Can't compile template %s: %s", tpl.name, err.Error())
+	}
+	var block *bytecode.InstructionSet
+	for _, set := range sets {
+		set.Filename = tpl.name
+		for i, line := range set.SourceMap {
+			set.SourceMap[i] = templateLine(line)
+		}
+		// The template is the first block in the code
+		if set.Type == bytecode.Block && set.Name == "0" {
+			block = set
+		}
+	}
+	tpl.compiled.put(key, block)
+	return block, nil
+}
+
+// render writes the template to out, with self and the locals of the binding
+func (tpl *TemplateObject) render(t *Thread, out *strings.Builder, self Object, locals *HashObject, depth int) *Error {
+	var names []string
+	if locals != nil {
+		for name := range locals.Pairs {
+			if templateLocal.MatchString(name) && token.LookupIdent(name) == token.Ident {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+	}
+
+	is, err := tpl.compile(t, names)
+	if err != nil {
+		return err
+	}
+
+	w := &templateWriter{
+		BaseObj: BaseObj{class: templateWriterClass},
+		tpl:     tpl,
+		out:     out,
+		self:    self,
+		locals:  locals,
+		depth:   depth,
+	}
+	var binding Object = NIL
+	if locals != nil {
+		binding = locals
+	}
+	cf := newNormalCallFrame(is, tpl.name, 1)
+	cf.self = self
+	cf.isBlock = true
+	defer func() {
+		if r := recover(); r != nil {
+			if err, ok := r.(*Error); ok {
+				tpl.attachLine(err)
+			}
+			panic(r)
+		}
+	}()
+	t.Yield(cf, binding, w)
+	return nil
+}
+
+// attachLine records on an error raised while rendering the template the line it
+// was raised on, unless a partial of the template has recorded its own line already
+func (tpl *TemplateObject) attachLine(err *Error) {
+	if _, ok := err.GetVariable("@template"); ok {
+		return
+	}
+	// The innermost frame of the template is the first in the stack trace
+	prefix := "from " + tpl.name + ":"
+	for _, trace := range err.stackTraces {
+		if !strings.HasPrefix(trace, prefix) {
+			continue
+		}
+		line, convErr := strconv.Atoi(strings.TrimPrefix(trace, prefix))
+		if convErr != nil {
+			continue
+		}
+		err.SetVariable("@template", StringObject(tpl.name))
+		err.SetVariable("@line", IntegerObject(line))
+		if kind := err.Type + ": "; strings.HasPrefix(err.message, kind) {
+			err.message = fmt.Sprintf("%s%s:%d: %s", kind, tpl.name, line, strings.TrimPrefix(err.message, kind))
+		}
+		return
+	}
+}
+
+// partial returns the registered partial with the name, or else the template in
+// the file of that name, relative to this template's file
+func (tpl *TemplateObject) partial(t *Thread, name string) (*TemplateObject, *Error) {
+	var partial Object
+	if p, ok := templatePartials.Load(name); ok {
+		switch p := p.(type) {
+		case *TemplateObject:
+			return p, nil
+		case StringObject:
+			partial = newTemplate(t, name, string(p), tpl.escape)
+		}
+	} else {
+		path := name
+		if !filepath.IsAbs(path) {
+			dir := t.vm.fileDir
+			if _, err := os.Stat(tpl.name); err == nil {
+				dir = filepath.Dir(tpl.name)
+			}
+			path = filepath.Join(dir, name)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil, t.vm.InitErrorObject(t, errors.TemplateError, "Partial not found: %s", name)
+		}
+		partial = loadTemplate(t, path, tpl.escape)
+	}
+	if err, ok := partial.(*Error); ok {
+		return nil, err
+	}
+	return partial.(*TemplateObject), nil
+}
+
+// Value returns the source of the template
+func (tpl *TemplateObject) Value() interface{} {
+	return tpl.source
+}
+
+// ToString returns the source of the template
+func (tpl *TemplateObject) ToString(t *Thread) string {
+	return tpl.source
+}
+
+// Inspect returns the name of the template
+func (tpl *TemplateObject) Inspect(t *Thread) string {
+	return "#<Template " + tpl.name + ">"
+}
+
+// ToJSON returns the source of the template as a JSON string
+func (tpl *TemplateObject) ToJSON(t *Thread) string {
+	return StringObject(tpl.source).ToJSON(t)
+}
+
+// EqualTo returns true if the objects are the same Template
+func (tpl *TemplateObject) EqualTo(with Object) bool {
+	return tpl == with
+}
+
+// Value returns the output so far
+func (w *templateWriter) Value() interface{} {
+	return w.out.String()
+}
+
+// ToString returns the output so far
+func (w *templateWriter) ToString(t *Thread) string {
+	return w.out.String()
+}
+
+// Inspect returns the name of the template being written
+func (w *templateWriter) Inspect(t *Thread) string {
+	return "#<TemplateWriter " + w.tpl.name + ">"
+}
+
+// ToJSON returns the output so far as a JSON string
+func (w *templateWriter) ToJSON(t *Thread) string {
+	return StringObject(w.out.String()).ToJSON(t)
+}
+
+// EqualTo returns true if the objects are the same writer
+func (w *templateWriter) EqualTo(with Object) bool {
+	return w == with
+}
+
+// lruCache holds up to a fixed number of values, dropping the least recently used
+// value to make room for a new one
+type lruCache struct {
+	mutex   sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// lruEntry is a key and its value, as kept in the order of an lruCache
+type lruEntry struct {
+	key   string
+	value interface{}
+}
+
+func newLRUCache(size int) *lruCache {
+	return &lruCache{
+		size:    size,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// get returns the value of the key, marking it as the most recently used
+func (c *lruCache) get(key string) (interface{}, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true
+}
+
+// put sets the value of the key, dropping the least recently used value when the cache is full
+func (c *lruCache) put(key string, value interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*lruEntry).value = value
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
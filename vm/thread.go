@@ -3,6 +3,7 @@ package vm
 import (
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/robotii/lito/compiler"
 	"github.com/robotii/lito/compiler/bytecode"
@@ -12,6 +13,10 @@ import (
 
 const mainThreadID = 0
 
+// maxCallDepth is the number of frames a thread can have on its call stack.
+// Deeper calls raise an error rather than overflowing the Go stack.
+const maxCallDepth = 100000
+
 // Thread is the context needed for a single thread of execution
 type Thread struct {
 	// a stack that holds call frames
@@ -20,6 +25,10 @@ type Thread struct {
 	currentFrame callFrame
 	// cachedFrame stores a per thread call frame for reuse
 	cachedFrame goCallFrame
+	// framePool holds released call frames for reuse
+	framePool []*CallFrame
+	// deferStack holds the deferred blocks of the frames run by the closure engine
+	deferStack []*CallFrame
 	// the current line being executed
 	currentLine int
 	// data Stack
@@ -87,6 +96,9 @@ func (t *Thread) evaluateGoFrame(cf *goCallFrame) {
 }
 
 func (t *Thread) evaluateNormalFrame(cf *CallFrame) {
+	if t.callFrameStack.pointer >= maxCallDepth {
+		t.pushErrorObject(errors.StackOverflowError, errors.CallStackTooDeep, maxCallDepth)
+	}
 	t.callFrameStack.push(cf)
 	defer func() {
 		if r := recover(); r != nil {
@@ -97,7 +109,11 @@ func (t *Thread) evaluateNormalFrame(cf *CallFrame) {
 	oldFrame := t.currentFrame
 	t.currentFrame = cf
 
-	t.execFrame(cf)
+	if t.vm.compile {
+		t.execCompiledFrame(cf)
+	} else {
+		t.execFrame(cf)
+	}
 	if !cf.IsRemoved() {
 		cf.isRemoved = true
 		t.callFrameStack.pop()
@@ -152,8 +168,11 @@ func (t *Thread) YieldWithBlockArgument(blockFrame *CallFrame, block *CallFrame,
 	if blockFrame.IsRemoved() {
 		return NIL
 	}
+	if blockFrame.native != nil {
+		return blockFrame.native(args)
+	}
 
-	c := newNormalCallFrame(blockFrame.instructionSet, blockFrame.FileName(), blockFrame.sourceLine)
+	c := t.newFrame(blockFrame.instructionSet, blockFrame.FileName(), blockFrame.sourceLine)
 	c.blockFrame = block
 	c.ep = blockFrame.ep
 	c.self = blockFrame.self
@@ -161,6 +180,7 @@ func (t *Thread) YieldWithBlockArgument(blockFrame *CallFrame, block *CallFrame,
 	c.initLocalsFrom(args...)
 
 	t.evaluateNormalFrame(c)
+	t.releaseFrame(c)
 
 	if blockFrame.IsRemoved() {
 		return NIL
@@ -191,7 +211,49 @@ func (t *Thread) sendMethod(methodName string, argCount int, blockFrame *CallFra
 
 	t.Stack.pointer--
 
-	t.FindAndExecute(receiver, methodName, false, receiverPr, argPr, argCount, nil, blockFrame, t.callFrameStack.top().FileName())
+	t.FindAndExecute(receiver, methodName, false, receiverPr, argPr, argCount, nil, blockFrame, t.callFrameStack.top().FileName(), nil)
+}
+
+// CallMethod calls the named method on the receiver with the given arguments and returns the result
+func (t *Thread) CallMethod(receiver Object, methodName string, args ...Object) Object {
+	return t.CallMethodWithBlock(receiver, methodName, nil, args...)
+}
+
+// CallMethodWithBlock calls the named method on the receiver, passing the block frame
+func (t *Thread) CallMethodWithBlock(receiver Object, methodName string, blockFrame *CallFrame, args ...Object) Object {
+	// The cached frame may still be in use by the primitive method calling us
+	cached := t.cachedFrame
+	defer func() {
+		t.cachedFrame = cached
+	}()
+
+	receiverPr := t.Stack.pointer
+	t.Stack.Push(receiver)
+	for _, arg := range args {
+		t.Stack.Push(arg)
+	}
+	t.FindAndExecute(receiver, methodName, false, receiverPr, receiverPr+1, len(args), nil, blockFrame, t.callFrameStack.top().FileName(), nil)
+	return t.Stack.Pop()
+}
+
+// keywordArguments splits the arguments of the builtin method being run into
+// those passed by keyword, and those passed by position
+func (t *Thread) keywordArguments(args []Object) (map[string]Object, []Object) {
+	keywords := map[string]Object{}
+	var positional []Object
+
+	cf, ok := t.currentFrame.(*goCallFrame)
+	for i, arg := range args {
+		if ok && cf.argSet != nil && i < len(cf.argSet.Types()) {
+			switch cf.argSet.Types()[i] {
+			case bytecode.RequiredKeywordArg, bytecode.OptionalKeywordArg:
+				keywords[cf.argSet.Names()[i]] = arg
+				continue
+			}
+		}
+		positional = append(positional, arg)
+	}
+	return keywords, positional
 }
 
 func (t *Thread) evalBuiltinMethod(receiver Object, method *BuiltinMethodObject, receiverPtr, argCount int, argSet *bytecode.ArgSet, blockFrame *CallFrame, fileName string) {
@@ -211,6 +273,7 @@ func (t *Thread) evalBuiltinMethod(receiver Object, method *BuiltinMethodObject,
 		)
 		cf = &t.cachedFrame
 	}
+	cf.argSet = argSet
 
 	t.evaluateGoFrame(cf)
 	evaluated := t.Stack.top()
@@ -240,10 +303,79 @@ func (t *Thread) evalBuiltinMethod(receiver Object, method *BuiltinMethodObject,
 }
 
 func (t *Thread) evalMethodCall(receiver Object, method *MethodObject, receiverPtr, argCount int, argSet *bytecode.ArgSet, blockFrame *CallFrame, sourceLine int) {
-	cf := newNormalCallFrame(method.instructionSet, method.instructionSet.Filename, sourceLine)
+	cf := t.newFrame(method.instructionSet, method.instructionSet.Filename, sourceLine)
 	cf.self = receiver
+	cf.ep = method.ep
 	cf.blockFrame = blockFrame
+	t.assignArguments(cf, method, receiverPtr, argCount, argSet)
+
+	t.evaluateNormalFrame(cf)
+	// A tail call may have replaced the method, so check the return against the one which returned
+	t.checkReturnClass(cf, receiverPtr)
 
+	// Put the return value on the stack
+	t.Stack.Set(receiverPtr, t.Stack.top())
+	t.Stack.pointer = receiverPtr + 1
+	t.releaseFrame(cf)
+}
+
+// tailCall makes a call in tail position by reusing the frame for the method called.
+// It returns false when the call has to be made normally.
+func (t *Thread) tailCall(cf *CallFrame, methodName string, argCount int, argSet *bytecode.ArgSet, site *bytecode.CallSite) bool {
+	// A binding may still refer to the frame's locals
+	if cf.bound {
+		return false
+	}
+	stack := &t.Stack
+	// Leave splatted arguments to a normal call
+	if argCount > 0 {
+		switch top := stack.top().(type) {
+		case *ArrayObject:
+			if top.splat {
+				return false
+			}
+		case *BlockObject:
+			if top.splat {
+				return false
+			}
+		}
+	}
+
+	argPr := stack.pointer - argCount
+	receiverPr := argPr - 1
+	receiver := stack.data[receiverPr]
+	super := stack.flags[receiverPr].has(superRef)
+	method, ok := findMethodAt(site, receiver, methodName, super).(*MethodObject)
+	// Leave checking the visibility to a normal call
+	if !ok || method.Visibility != Public && !site.SelfReceiver {
+		return false
+	}
+
+	// The arguments are on the stack, so the locals can be cleared before assigning them
+	for i := range cf.locals {
+		cf.locals[i] = nil
+	}
+	cf.locals = cf.locals[:0]
+	t.assignArguments(cf, method, receiverPr, argCount, argSet)
+	stack.pointer = receiverPr
+
+	is := method.instructionSet
+	if is.LocalsCaptured && cf.lock == nil {
+		cf.lock = &sync.Mutex{}
+	}
+	cf.instructionSet = is
+	cf.fileName = is.Filename
+	cf.sourceLine = t.GetSourceLine()
+	cf.self = receiver
+	cf.ep = method.ep
+	cf.blockFrame = nil
+	cf.pc = 0
+	return true
+}
+
+// assignArguments checks the arguments on the stack against the method's parameters,
+// and assigns them to the frame's locals
+func (t *Thread) assignArguments(cf *CallFrame, method *MethodObject, receiverPtr, argCount int, argSet *bytecode.ArgSet) {
 	call := callObject{
 		method:      method,
 		receiverPtr: receiverPtr,
@@ -303,11 +435,9 @@ func (t *Thread) evalMethodCall(receiver Object, method *MethodObject, receiverP
 		call.assignNormalArguments(stack)
 	}
 
-	t.evaluateNormalFrame(call.callFrame)
-
-	// Put the return value on the stack
-	t.Stack.Set(call.receiverPtr, t.Stack.top())
-	t.Stack.pointer = call.argPtr()
+	if method.instructionSet.ArgTypes.Classes() != nil {
+		t.checkArgumentClasses(cf, method, receiverPtr)
+	}
 }
 
 func (t *Thread) reportArgumentError(idealArgNumber int, methodName string, exactArgNumber int, receiverPtr int) {
@@ -336,9 +466,10 @@ func (t *Thread) setErrorObject(receiverPtr, sp int, errorType string, format st
 	panic(err)
 }
 
-// FindAndExecute finds and executes a method
-func (t *Thread) FindAndExecute(receiver Object, methodName string, super bool, receiverPr int, argPr int, argCount int, argSet *bytecode.ArgSet, blockFrame *CallFrame, fileName string) {
-	method := receiver.FindMethod(methodName, super)
+// FindAndExecute finds and executes a method. The call site, which may be nil, caches
+// the method found.
+func (t *Thread) FindAndExecute(receiver Object, methodName string, super bool, receiverPr int, argPr int, argCount int, argSet *bytecode.ArgSet, blockFrame *CallFrame, fileName string, site *bytecode.CallSite) {
+	method := findMethodAt(site, receiver, methodName, super)
 
 	if method == nil {
 		mm := receiver.FindLookup(receiver.Class().inheritsLookup)
@@ -356,6 +487,8 @@ func (t *Thread) FindAndExecute(receiver Object, methodName string, super bool,
 		}
 	}
 
+	t.checkVisibility(method, receiver, methodName, receiverPr, argPr, site)
+
 	switch m := method.(type) {
 	case *MethodObject:
 		t.evalMethodCall(receiver, m, receiverPr, argCount, argSet, blockFrame, t.GetSourceLine())
@@ -0,0 +1,163 @@
+package vm
+
+import (
+	"bytes"
+	"sort"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+var tomlClassMethods = []*BuiltinMethodObject{
+	{
+		Name:      "new",
+		Fn:        NoSuchMethod("new"),
+		Primitive: true,
+	},
+	{
+		// Parses a TOML document into a Hash.
+		// Dates and times are returned as Strings in their TOML format.
+		// A TOMLError is raised if the string is not valid TOML.
+		Name: "parse",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			s, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+			}
+
+			var m map[string]interface{}
+			md, err := toml.Decode(string(s), &m)
+			if err != nil {
+				return t.vm.InitErrorObject(t, errors.TOMLError, "Can't parse TOML: %s", err.Error())
+			}
+			return t.vm.InitObjectFromGoType(tomlToGo(m, nil, tomlKeyOrder(md)))
+		},
+	},
+	{
+		// Decodes a TOML document from a File into a Hash.
+		Name: "load",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			f, ok := args[0].(*FileObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.FileClass, args[0].Class().Name)
+			}
+
+			var m map[string]interface{}
+			md, err := toml.NewDecoder(f.File).Decode(&m)
+			if err != nil {
+				return t.vm.InitErrorObject(t, errors.TOMLError, "Can't parse TOML from %s: %s", f.File.Name(), err.Error())
+			}
+			return t.vm.InitObjectFromGoType(tomlToGo(m, nil, tomlKeyOrder(md)))
+		},
+	},
+	{
+		// Generates a TOML document from a Hash.
+		// TOML has no null, so nil values are left out.
+		Name: "generate",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			if _, ok := args[0].(*HashObject); !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.HashClass, args[0].Class().Name)
+			}
+
+			v, err := serialisableGoValue(t, args[0])
+			if err != nil {
+				return t.vm.InitErrorObject(t, errors.TOMLError, "Can't generate TOML from %s: %s", args[0].Class().Name, err.Error())
+			}
+
+			var out bytes.Buffer
+			if err := toml.NewEncoder(&out).Encode(v); err != nil {
+				return t.vm.InitErrorObject(t, errors.TOMLError, "Can't generate TOML from %s: %s", args[0].Class().Name, err.Error())
+			}
+			return StringObject(out.String())
+		},
+	},
+}
+
+var tomlInstanceMethods = []*BuiltinMethodObject{}
+
+func initTOMLClass(vm *VM) {
+	class := vm.InitClass("TOML").
+		ClassMethods(tomlClassMethods).
+		InstanceMethods(tomlInstanceMethods)
+	vm.TopLevelClass(classes.ObjectClass).SetClassConstant(class)
+}
+
+// tomlKeyOrder returns the keys of each table in the order they appear in the document,
+// by the path of the table. The tables in an array of tables share the same path.
+func tomlKeyOrder(md toml.MetaData) map[string][]string {
+	order := map[string][]string{}
+	seen := map[string]bool{}
+	for _, key := range md.Keys() {
+		if seen[key.String()] {
+			continue
+		}
+		seen[key.String()] = true
+		parent := key[:len(key)-1].String()
+		order[parent] = append(order[parent], key[len(key)-1])
+	}
+	return order
+}
+
+// tomlToGo converts the types produced by the TOML decoder into ones InitObjectFromGoType understands,
+// putting the keys of the table at path in the order given
+func tomlToGo(value interface{}, path toml.Key, order map[string][]string) interface{} {
+	switch val := value.(type) {
+	case map[string]interface{}:
+		keys := order[path.String()]
+		if len(keys) < len(val) {
+			// Keys the decoder didn't report follow in sorted order
+			rest := make([]string, 0, len(val))
+			for k := range val {
+				rest = append(rest, k)
+			}
+			sort.Strings(rest)
+			keys = append(keys[:len(keys):len(keys)], rest...)
+		}
+		m := newOrderedMap()
+		for _, k := range keys {
+			if elem, ok := val[k]; ok {
+				if _, done := m.values[k]; !done {
+					m.set(k, tomlToGo(elem, append(path[:len(path):len(path)], k), order))
+				}
+			}
+		}
+		return m
+	case []interface{}:
+		for i, elem := range val {
+			val[i] = tomlToGo(elem, path, order)
+		}
+	case []map[string]interface{}:
+		// Arrays of tables
+		a := make([]interface{}, len(val))
+		for i, elem := range val {
+			a[i] = tomlToGo(elem, path, order)
+		}
+		return a
+	case time.Time:
+		// The decoder marks local dates and times with named zones
+		switch val.Location().String() {
+		case "datetime-local":
+			return val.Format("2006-01-02T15:04:05.999999999")
+		case "date-local":
+			return val.Format("2006-01-02")
+		case "time-local":
+			return val.Format("15:04:05.999999999")
+		}
+		return val.Format(time.RFC3339Nano)
+	}
+	return value
+}
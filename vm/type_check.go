@@ -0,0 +1,64 @@
+package vm
+
+import (
+	"strings"
+
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+// annotatedClass returns the class a type annotation names, looked up from the method's frame
+func (t *Thread) annotatedClass(cf *CallFrame, name string) *RClass {
+	names := strings.Split(name, "::")
+	c := cf.lookupConstantUnderAllScope(names[0])
+	if c == nil {
+		c = t.vm.objectClass.constants[names[0]]
+	}
+	for _, n := range names[1:] {
+		if c == nil {
+			break
+		}
+		namespace, ok := c.Target.(*RClass)
+		if !ok {
+			break
+		}
+		c = namespace.constants[n]
+	}
+
+	if c == nil {
+		t.pushErrorObject(errors.NameError, "uninitialised constant '%s'", name)
+	}
+	class, ok := c.Target.(*RClass)
+	if !ok {
+		t.pushErrorObject(errors.TypeError, errors.WrongArgumentTypeFormat, classes.ClassClass, c.Target.Class().Name)
+	}
+	return class
+}
+
+// checkArgumentClasses raises a TypeError if an argument isn't of the class its parameter is annotated with
+func (t *Thread) checkArgumentClasses(cf *CallFrame, method *MethodObject, receiverPtr int) {
+	argTypes := method.instructionSet.ArgTypes
+	for i, name := range argTypes.Classes() {
+		if name == "" {
+			continue
+		}
+		arg := cf.localValue(i, 0)
+		if !arg.Class().isA(t.annotatedClass(cf, name)) {
+			t.setErrorObject(receiverPtr, receiverPtr+1, errors.TypeError, errors.WrongParameterType, argTypes.Names()[i], method.Name, name, arg.Class().Name)
+		}
+	}
+}
+
+// checkReturnClass raises a TypeError if the value returned by the frame's method isn't
+// of the class the method is annotated to return
+func (t *Thread) checkReturnClass(cf *CallFrame, receiverPtr int) {
+	is := cf.instructionSet
+	name := is.ArgTypes.ReturnClass()
+	if name == "" {
+		return
+	}
+	value := t.Stack.top()
+	if !value.Class().isA(t.annotatedClass(cf, name)) {
+		t.setErrorObject(receiverPtr, receiverPtr+1, errors.TypeError, errors.WrongReturnType, is.Name, name, value.Class().Name)
+	}
+}
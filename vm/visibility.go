@@ -0,0 +1,134 @@
+package vm
+
+import (
+	"github.com/robotii/lito/compiler/bytecode"
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+)
+
+// Visibility controls where a method can be called from
+type Visibility uint8
+
+const (
+	// Public methods can be called from anywhere
+	Public Visibility = iota
+	// Protected methods can be called on self, or from methods of the class which made them protected
+	Protected
+	// Private methods can only be called on self
+	Private
+)
+
+var visibilityNames = map[string]Visibility{
+	"public":    Public,
+	"protected": Protected,
+	"private":   Private,
+}
+
+// methodVisibility returns the visibility of the method, and the class which set it
+func methodVisibility(method Object) (Visibility, *RClass) {
+	switch m := method.(type) {
+	case *MethodObject:
+		return m.Visibility, m.owner
+	case *BuiltinMethodObject:
+		return m.Visibility, m.owner
+	}
+	return Public, nil
+}
+
+// withVisibility returns a copy of the method with the visibility, as methods may be
+// shared by several classes
+func withVisibility(method Object, v Visibility, owner *RClass) Object {
+	switch m := method.(type) {
+	case *MethodObject:
+		c := *m
+		c.Visibility, c.owner = v, owner
+		return &c
+	case *BuiltinMethodObject:
+		c := *m
+		c.Visibility, c.owner = v, owner
+		return &c
+	}
+	return method
+}
+
+// checkVisibility raises a NoMethodError if the method can't be called from the call site
+func (t *Thread) checkVisibility(method, receiver Object, methodName string, receiverPr, argPr int, site *bytecode.CallSite) {
+	// Calls without a call site are made by the VM itself, or by send
+	if site == nil || site.SelfReceiver {
+		return
+	}
+	switch v, owner := methodVisibility(method); v {
+	case Private:
+		t.setErrorObject(receiverPr, argPr, errors.NoMethodError, errors.PrivateMethod, methodName, receiver.ToString(t))
+	case Protected:
+		if owner != nil {
+			if self := t.callFrameStack.top().Self(); self != nil && self.Class().isA(owner) {
+				return
+			}
+		}
+		t.setErrorObject(receiverPr, argPr, errors.NoMethodError, errors.ProtectedMethod, methodName, receiver.ToString(t))
+	}
+}
+
+// setVisibility gives the named methods of the class the visibility, or the methods
+// defined by the block if there are no names
+func setVisibility(receiver Object, t *Thread, args []Object, v Visibility) Object {
+	class, ok := receiver.(*RClass)
+	if !ok {
+		return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.ClassClass, receiver.Class().Name)
+	}
+
+	if len(args) == 0 {
+		blockFrame := t.GetBlock()
+		if blockFrame == nil || blockFrame.IsEmpty() {
+			return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentMore, 1, 0)
+		}
+
+		before := make(map[string]Object, len(class.Methods))
+		for name, method := range class.Methods {
+			before[name] = method
+		}
+		blockFrame.self = class
+		t.Yield(blockFrame)
+
+		for name, method := range class.Methods {
+			if before[name] != method {
+				class.setMethod(name, withVisibility(method, v, class))
+			}
+		}
+		return class
+	}
+
+	for _, arg := range args {
+		name, ok := arg.(StringObject)
+		if !ok {
+			return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, arg.Class().Name)
+		}
+		method := class.lookupMethod(string(name))
+		if method == nil {
+			return t.vm.InitErrorObject(t, errors.NameError, errors.UndefinedMethod, string(name), class.Name)
+		}
+		class.setMethod(string(name), withVisibility(method, v, class))
+	}
+	return class
+}
+
+// visibilityFilter returns the filter for the methods with the visibility named by the
+// argument, or for the methods which aren't private if there is no argument
+func visibilityFilter(t *Thread, args []Object) (func(Visibility) bool, *Error) {
+	switch len(args) {
+	case 0:
+		return func(v Visibility) bool { return v != Private }, nil
+	case 1:
+		name, ok := args[0].(StringObject)
+		if !ok {
+			return nil, t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+		}
+		visibility, ok := visibilityNames[string(name)]
+		if !ok {
+			return nil, t.vm.InitErrorObject(t, errors.ArgumentError, "Unknown visibility: %s", string(name))
+		}
+		return func(v Visibility) bool { return v == visibility }, nil
+	}
+	return nil, t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgumentLess, 1, len(args))
+}
@@ -52,26 +52,44 @@ var standardClasses = map[string]ClassInitFunc{
 }
 
 var baseClasses = map[string]ClassInitFunc{
-	"Method":    initMethodClass,
-	"Integer":   initIntegerClass,
-	"Float":     initFloatClass,
-	"String":    initStringClass,
-	"Boolean":   initBoolClass,
-	"Nil":       initNilClass,
-	"Array":     initArrayClass,
-	"Hash":      initHashClass,
-	"Range":     initRangeClass,
-	"Block":     initBlockClass,
-	"Channel":   initChannelClass,
-	"GoObject":  initGoClass,
-	"WaitGroup": initWaitGroupClass,
-	"Regexp":    initRegexpClass,
+	"Method":        initMethodClass,
+	"Integer":       initIntegerClass,
+	"Float":         initFloatClass,
+	"String":        initStringClass,
+	"StringBuilder": initStringBuilderClass,
+	"Boolean":       initBoolClass,
+	"Nil":           initNilClass,
+	"Array":         initArrayClass,
+	"Hash":          initHashClass,
+	"Range":         initRangeClass,
+	"Block":         initBlockClass,
+	"Bytes":         initBytesClass,
+	"Math":          initMathClass,
+	"Decimal":       initDecimalClass,
+	"Rational":      initRationalClass,
+	"Generator":     initGeneratorClass,
+	"Lazy":          initLazyClass,
+	"Set":           initSetClass,
+	"Struct":        initStructClass,
+	"Channel":       initChannelClass,
+	"GoObject":      initGoClass,
+	"WaitGroup":     initWaitGroupClass,
+	"Regexp":        initRegexpClass,
+	"MatchData":     initMatchDataClass,
+	"Binding":       initBindingClass,
 }
 
 var standardLibraries = map[string]func(*VM){
-	"json": initJSONClass,
-	"lock": initLockClass,
-	"spec": initSpecClass,
+	"csv":      initCSVClass,
+	"digest":   initDigestClass,
+	"encoding": initEncodingClasses,
+	"json":     initJSONClass,
+	"lock":     initLockClass,
+	"random":   initRandomClass,
+	"spec":     initSpecClass,
+	"template": initTemplateClass,
+	"toml":     initTOMLClass,
+	"yaml":     initYAMLClass,
 }
 
 // VM represents a stack based virtual machine.
@@ -100,12 +118,16 @@ type VM struct {
 	// threadCount holds the count of threads that have been created.
 	// It is never reset.
 	threadCount int64
+	// compile is set when frames are run by compiling their instructions to closures,
+	// rather than by the interpreter
+	compile bool
 }
 
 // MachineConfigs a list of different machine configurations
 var MachineConfigs = map[string]ConfigFunc{
 	"standard": standard,
 	"sandbox":  sandbox,
+	"compiled": compiled,
 }
 
 // New initialises a vm to initial state and returns it.
@@ -215,9 +237,17 @@ func standard(vm *VM) error {
 	return nil
 }
 
+// compiled initialises a standard VM which compiles instructions to closures
+func compiled(vm *VM) error {
+	vm.compile = true
+	return standard(vm)
+}
+
 // sandbox initialises a sandboxed VM
 func sandbox(vm *VM) error {
 	vm.initConstants()
+	// Init builtin modules, which the builtin classes may include
+	vm.objectClass.SetClassConstant(initEnumerableModule(vm))
 	// Init builtin classes
 	for _, initFunc := range baseClasses {
 		// Call the init function and store constant
@@ -0,0 +1,193 @@
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/robotii/lito/vm/classes"
+	"github.com/robotii/lito/vm/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var yamlClassMethods = []*BuiltinMethodObject{
+	{
+		Name:      "new",
+		Fn:        NoSuchMethod("new"),
+		Primitive: true,
+	},
+	{
+		// Parses a YAML document into the equivalent Lito objects.
+		// A YAMLError is raised if the string is not valid YAML.
+		Name: "parse",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			s, ok := args[0].(StringObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.StringClass, args[0].Class().Name)
+			}
+
+			var n yaml.Node
+			err := yaml.Unmarshal([]byte(s), &n)
+			if err == nil {
+				var o interface{}
+				if o, err = yamlNodeToGo(&n); err == nil {
+					return t.vm.InitObjectFromGoType(o)
+				}
+			}
+			return t.vm.InitErrorObject(t, errors.YAMLError, "Can't parse YAML: %s", err.Error())
+		},
+	},
+	{
+		// Decodes the first YAML document in a File.
+		Name: "load",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			f, ok := args[0].(*FileObject)
+			if !ok {
+				return t.vm.InitErrorObject(t, errors.TypeError, errors.WrongArgumentTypeFormat, classes.FileClass, args[0].Class().Name)
+			}
+
+			var n yaml.Node
+			err := yaml.NewDecoder(f.File).Decode(&n)
+			if err == nil {
+				var o interface{}
+				if o, err = yamlNodeToGo(&n); err == nil {
+					return t.vm.InitObjectFromGoType(o)
+				}
+			}
+			return t.vm.InitErrorObject(t, errors.YAMLError, "Can't parse YAML from %s: %s", f.File.Name(), err.Error())
+		},
+	},
+	{
+		// Generates a YAML document from the given object.
+		// Objects are converted in the same way as for JSON, so the `to_json` and `as_json` hooks apply.
+		Name: "generate",
+		Fn: func(receiver Object, t *Thread, args []Object) Object {
+			if len(args) != 1 {
+				return t.vm.InitErrorObject(t, errors.ArgumentError, errors.WrongNumberOfArgument, 1, len(args))
+			}
+
+			v, err := serialisableGoValue(t, args[0])
+			if err != nil {
+				return t.vm.InitErrorObject(t, errors.YAMLError, "Can't generate YAML from %s: %s", args[0].Class().Name, err.Error())
+			}
+
+			var out bytes.Buffer
+			enc := yaml.NewEncoder(&out)
+			enc.SetIndent(2)
+			if err := enc.Encode(v); err != nil {
+				return t.vm.InitErrorObject(t, errors.YAMLError, "Can't generate YAML from %s: %s", args[0].Class().Name, err.Error())
+			}
+			return StringObject(out.String())
+		},
+	},
+}
+
+var yamlInstanceMethods = []*BuiltinMethodObject{}
+
+func initYAMLClass(vm *VM) {
+	class := vm.InitClass("YAML").
+		ClassMethods(yamlClassMethods).
+		InstanceMethods(yamlInstanceMethods)
+	vm.TopLevelClass(classes.ObjectClass).SetClassConstant(class)
+}
+
+// yamlNodeToGo converts a decoded YAML node into Go values, keeping the keys of mappings
+// in document order. YAML allows keys which aren't strings, which are turned into strings.
+func yamlNodeToGo(n *yaml.Node) (interface{}, error) {
+	switch n.Kind {
+	case 0:
+		// An empty document
+		return nil, nil
+	case yaml.DocumentNode:
+		return yamlNodeToGo(n.Content[0])
+	case yaml.AliasNode:
+		return yamlNodeToGo(n.Alias)
+	case yaml.SequenceNode:
+		a := make([]interface{}, len(n.Content))
+		for i, elem := range n.Content {
+			value, err := yamlNodeToGo(elem)
+			if err != nil {
+				return nil, err
+			}
+			a[i] = value
+		}
+		return a, nil
+	case yaml.MappingNode:
+		m := newOrderedMap()
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Tag == "!!merge" {
+				if err := yamlMerge(m, n.Content[i+1]); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			key, err := yamlNodeToGo(n.Content[i])
+			if err != nil {
+				return nil, err
+			}
+			value, err := yamlNodeToGo(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			m.set(fmt.Sprint(key), value)
+		}
+		return m, nil
+	}
+
+	var value interface{}
+	if err := n.Decode(&value); err != nil {
+		return nil, err
+	}
+	return yamlToGo(value), nil
+}
+
+// yamlMerge adds the keys of the mappings given to a `<<` key which the mapping doesn't
+// already have. Keys written in the mapping itself replace the merged values.
+func yamlMerge(m *orderedMap, n *yaml.Node) error {
+	if n.Kind == yaml.SequenceNode {
+		for _, elem := range n.Content {
+			if err := yamlMerge(m, elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	value, err := yamlNodeToGo(n)
+	if err != nil {
+		return err
+	}
+	merged, ok := value.(*orderedMap)
+	if !ok {
+		return fmt.Errorf("map merge requires map or sequence of maps as the value")
+	}
+	for _, k := range merged.keys {
+		if _, ok := m.values[k]; !ok {
+			m.set(k, merged.values[k])
+		}
+	}
+	return nil
+}
+
+// yamlToGo replaces timestamps with Strings, and integers too large for an int with big integers
+func yamlToGo(value interface{}) interface{} {
+	switch val := value.(type) {
+	case uint64:
+		return new(big.Int).SetUint64(val)
+	case time.Time:
+		// Timestamps are returned as Strings, keeping plain dates as they were written
+		if val.Location() == time.UTC && val.Equal(val.Truncate(24*time.Hour)) {
+			return val.Format("2006-01-02")
+		}
+		return val.Format(time.RFC3339Nano)
+	}
+	return value
+}